@@ -1,12 +1,34 @@
 package accumulator
 
 import (
+	"errors"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/Nigel2392/go-datastructures/linkedlist"
 	"github.com/Nigel2392/go-datastructures/stack"
 )
 
+// ResetMode controls when the flush interval of an accumulator is reset.
+type ResetMode int
+
+const (
+	// NoReset never resets the flush interval, the queue is flushed at a fixed interval.
+	NoReset ResetMode = iota
+
+	// ResetOnPush resets the flush interval on every push.
+	//
+	// The queue is only flushed by the interval once pushes stop for a full interval,
+	// so a steady producer which never reaches the flush size delays the items indefinitely.
+	ResetOnPush
+
+	// ResetOnFlush resets the flush interval after a push which flushed the queue.
+	//
+	// Partial batches are still flushed when the interval passes.
+	ResetOnFlush
+)
+
 // An accumulator adds a number of items to a queue and
 // flushes the queue when the queue is full or a certain time has passed.
 //
@@ -21,77 +43,272 @@ type Accumulator[T any] struct {
 	// The time to wait before flushing the queue.
 	FlushInterval time.Duration
 
-	// Reset the flush interval after a push.
+	// The maximum amount of items passed to a single call of the FlushFunc.
+	//
+	// Larger flushes are split into multiple calls, preserving the order of the items.
+	// A value <= 0 passes all items at once.
+	MaxBatchSize int
+
+	// The maximum amount of calls to the FlushFunc which may run at the same time.
+	//
+	// Flushes are serialized, see FlushFunc, so a single accumulator runs at most one call at a time
+	// and any limit of 1 or more is always met. The limit is still enforced around every call with a semaphore.
+	// A value <= 0 does not limit the concurrent flushes.
+	MaxConcurrentFlushes int
+
+	// When the flush interval is reset, see ResetMode.
+	ResetMode ResetMode
+
+	// Reset the flush interval after a push which flushed the queue.
+	//
+	// Deprecated: Use ResetMode ResetOnFlush instead.
 	ResetAfterPush bool
 
+	// FlushFirstImmediately flushes the first item pushed after an idle period right away,
+	// instead of waiting for the flush size or interval.
+	//
+	// The accumulator is idle when nothing has been pushed for the flush interval.
+	FlushFirstImmediately bool
+
+	// AdaptiveRate is the push rate in items per second above which the flush interval is shortened,
+	// to flush more eagerly under load. The more the rate is exceeded, the shorter the interval.
+	//
+	// A value <= 0 disables adaptive flushing.
+	AdaptiveRate float64
+
+	// The sliding window over which the push rate is measured, defaults to the FlushInterval.
+	AdaptiveWindow time.Duration
+
+	// The shortest flush interval under adaptive flushing, defaults to a tenth of the FlushInterval.
+	AdaptiveMinInterval time.Duration
+
+	// The initial items of NewAccumulatorWithInitial which were not flushed yet,
+	// they are flushed before the items in the queue.
+	backlog []T
+
+	// The time of the last push.
+	lastPush time.Time
+
+	// The flush interval in effect under adaptive flushing,
+	// and the push counts of the current and previous window.
+	effectiveInterval time.Duration
+	windowStart       time.Time
+	pushesCurrent     int
+	pushesPrevious    int
+
 	// ticker is a ticker which is used to flush the queue.
-	ticker *time.Ticker
+	ticker ticker
+
+	// The clock which provides the time and the ticker.
+	clock clock
 
 	// The mutex used to lock the queue.
 	mutex *sync.Mutex
 
+	// The semaphore which limits the concurrent flushes, created on the first flush.
+	semaphore     chan struct{}
+	semaphoreOnce *sync.Once
+
 	// closeChan is a channel which is closed when the batch is closed.
 	closeChan chan struct{}
 
+	// The circuit breaker around the FlushErrFunc.
+	breaker *breaker
+
+	// closeOnce makes sure the accumulator is only closed once.
+	closeOnce *sync.Once
+
+	// Whether the accumulator is closed, and the amount of items pushed after it was closed.
+	closed  bool
+	dropped int
+
 	// The function which is called when the queue is flushed.
+	//
+	// Flushes are serialized: the FlushFunc is never called concurrently, and pushes wait for a running flush.
 	FlushFunc func([]T)
+
+	// FlushErrFunc is like FlushFunc, but may return an error if the items could not be flushed.
+	//
+	// If set, it is called instead of FlushFunc.
+	FlushErrFunc func([]T) error
+
+	// BreakerThreshold is the amount of consecutive failed flushes after which the circuit breaker opens.
+	//
+	// While open, flushes fail fast with ErrCircuitOpen, and the items are passed to the FallbackFunc.
+	// After the BreakerCooldown, a single flush is attempted, which closes the circuit if it succeeds.
+	// A value <= 0 disables the circuit breaker.
+	BreakerThreshold int
+
+	// The time the circuit breaker stays open before a flush is attempted again, defaults to the FlushInterval.
+	BreakerCooldown time.Duration
+
+	// FallbackFunc is called with the items of flushes which failed fast, because the circuit breaker is open.
+	FallbackFunc func([]T)
+
+	// OnDelivered is called with the items which were flushed successfully.
+	//
+	// It is not called for items for which the FlushErrFunc returned an error.
+	OnDelivered func([]T)
+
+	// Dedup returns the key of an item, items with the same key are collapsed within a single flush.
+	//
+	// Only the first pushed item of each key is flushed. If nil, items are not deduplicated.
+	Dedup func(T) string
+
+	// DedupMerge is called with the kept item of a key and the amount of items collapsed into it,
+	// including the item itself. The returned item is flushed instead, for example with the count added.
+	DedupMerge func(item T, count int) T
+
+	// Less reports whether item a should be flushed before item b.
+	//
+	// If set, the items are sorted by priority before they are passed to the FlushFunc.
+	Less func(a, b T) bool
 }
 
 // NewAccumulator creates a new accumulator which accumulates items and flushes them when the flush size is reached or the flush interval is reached.
 func NewAccumulator[T any](flushSize int, flushInterval time.Duration, flushFunc func([]T)) *Accumulator[T] {
+	return newAccumulator(flushSize, flushInterval, flushFunc, systemClock{})
+}
+
+// newAccumulator creates a new accumulator like NewAccumulator, which takes the time and its ticker from the clock.
+func newAccumulator[T any](flushSize int, flushInterval time.Duration, flushFunc func([]T), clock clock) *Accumulator[T] {
 	var a = &Accumulator[T]{
 		FlushSize:     flushSize,
 		FlushInterval: flushInterval,
 		FlushFunc:     flushFunc,
 		Queue:         stack.Stack[T]{},
 		mutex:         &sync.Mutex{},
+		semaphoreOnce: &sync.Once{},
 		closeChan:     make(chan struct{}),
+		closeOnce:     &sync.Once{},
+		breaker:       newBreaker(),
+		clock:         clock,
 	}
-	a.ticker = time.NewTicker(flushInterval)
+	a.ticker = clock.NewTicker(flushInterval)
 	go a.worker()
 	return a
 }
 
+// NewAccumulatorWithInitial creates a new accumulator like NewAccumulator, with the queue preloaded with the initial items.
+//
+// This can be used to retry a backlog of items which were persisted before a restart.
+// The initial items are flushed in the order they are given, before any item pushed later,
+// and right away if they reach the flush size.
+func NewAccumulatorWithInitial[T any](initial []T, flushSize int, flushInterval time.Duration, flushFunc func([]T)) *Accumulator[T] {
+	var a = NewAccumulator(flushSize, flushInterval, flushFunc)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.backlog = append([]T(nil), initial...)
+	if a.queued() >= a.FlushSize {
+		a.flushLocked()
+	}
+	return a
+}
+
+// queued returns the amount of items waiting to be flushed, the mutex must be held by the caller.
+func (a *Accumulator[T]) queued() int {
+	return len(a.backlog) + a.Queue.Len()
+}
+
+// worker flushes the queue when the flush interval passes.
+//
+// Flushes for the flush size are done by Push, when the item which reaches it is pushed.
 func (a *Accumulator[T]) worker() {
 	for {
 		select {
 		case <-a.closeChan:
 			return
-		case <-a.ticker.C:
-			a.Flush()
-		default:
-			a.mutex.Lock()
-			var needsFlush bool = a.Queue.Len() >= a.FlushSize
-			if needsFlush {
-				a.Flush()
-			}
-			a.mutex.Unlock()
-			time.Sleep(a.FlushInterval / 10)
+		case <-a.ticker.C():
+			a.tick()
 		}
 	}
 }
 
+// tick flushes the queue because the flush interval passed.
+func (a *Accumulator[T]) tick() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.adaptInterval(a.clock.Now())
+	a.flushLocked()
+}
+
 // Push adds an item to the accumulator.
 func (a *Accumulator[T]) Push(item T) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
+	if a.closed {
+		a.dropped++
+		return
+	}
+	var now = a.clock.Now()
+	var idle = a.queued() == 0 && now.Sub(a.lastPush) >= a.interval()
+	a.lastPush = now
+	if a.AdaptiveRate > 0 {
+		a.countPush(now)
+		a.adaptInterval(now)
+	}
 	a.Queue.Push(item)
-	var needsFlush bool = a.Queue.Len() >= a.FlushSize
+	var needsFlush bool = a.queued() >= a.FlushSize || (a.FlushFirstImmediately && idle)
 	if needsFlush {
-		a.Flush()
+		a.flushLocked()
+	}
+	switch a.resetMode() {
+	case ResetOnPush:
+		a.ticker.Reset(a.interval())
+	case ResetOnFlush:
+		if needsFlush {
+			a.ticker.Reset(a.interval())
+		}
 	}
-	if a.ResetAfterPush {
-		a.ticker.Reset(a.FlushInterval)
+}
+
+// resetMode returns the ResetMode in effect, taking the deprecated ResetAfterPush into account.
+func (a *Accumulator[T]) resetMode() ResetMode {
+	if a.ResetMode == NoReset && a.ResetAfterPush {
+		return ResetOnFlush
 	}
+	return a.ResetMode
 }
 
 // Flush flushes the queue.
+//
+// It waits for a push or flush which is in progress to complete first,
+// so Flush must not be called from inside the FlushFunc.
 func (a *Accumulator[T]) Flush() {
-	var lockedHere = a.mutex.TryLock()
-	if lockedHere {
-		defer a.mutex.Unlock()
-	}
-	var items = make([]T, 0, a.Queue.Len())
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.flushLocked()
+}
+
+// FlushSync flushes the queue like Flush, and returns once the flushed items have been handled.
+//
+// The error returned by the FlushErrFunc is returned.
+//
+// FlushSync must not be called from inside the FlushFunc.
+func (a *Accumulator[T]) FlushSync() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.flushLocked()
+}
+
+// Snapshot returns a copy of the queued items, in the order they will be flushed, without removing them.
+//
+// The items can be persisted and passed to NewAccumulatorWithInitial to recover them after a crash.
+// Items pushed or flushed after the snapshot was taken are not reflected in it,
+// so after a restart, items may be lost or flushed twice, depending on what happened since the last snapshot.
+func (a *Accumulator[T]) Snapshot() []T {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var items = append([]T(nil), a.backlog...)
+	return append(items, (*linkedlist.Singly[T])(&a.Queue).ToSlice()...)
+}
+
+// flushLocked flushes the queue, the mutex must be held by the caller.
+func (a *Accumulator[T]) flushLocked() error {
+	var items = make([]T, 0, a.queued())
+	var backlog = len(a.backlog)
+	items = append(items, a.backlog...)
+	a.backlog = nil
 	for {
 		item, ok := a.Queue.PopOK()
 		if !ok {
@@ -99,13 +316,123 @@ func (a *Accumulator[T]) Flush() {
 		}
 		items = append(items, item)
 	}
-	if len(items) > 0 {
+	if len(items) == 0 {
+		return nil
+	}
+	if a.Dedup != nil {
+		items = a.dedup(items, backlog)
+	}
+	if a.Less != nil {
+		sort.SliceStable(items, func(i, j int) bool {
+			return a.Less(items[i], items[j])
+		})
+	}
+	if a.MaxBatchSize <= 0 || len(items) <= a.MaxBatchSize {
+		return a.handle(items)
+	}
+	var errs []error
+	for len(items) > 0 {
+		var n = a.MaxBatchSize
+		if n > len(items) {
+			n = len(items)
+		}
+		if err := a.handle(items[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		items = items[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// dedup collapses the items with the same key, keeping the first pushed item of each key.
+//
+// The items start with the given amount of backlog items, which were pushed first.
+func (a *Accumulator[T]) dedup(items []T, backlog int) []T {
+	var keys = make([]string, len(items))
+	var counts = make(map[string]int)
+	var first = make(map[string]int)
+	// The backlog is in the order it was given, but the queue is a stack, the first pushed item of a key comes last.
+	var pushOrder = make([]int, 0, len(items))
+	for i := 0; i < backlog; i++ {
+		pushOrder = append(pushOrder, i)
+	}
+	for i := len(items) - 1; i >= backlog; i-- {
+		pushOrder = append(pushOrder, i)
+	}
+	for _, i := range pushOrder {
+		var key = a.Dedup(items[i])
+		if _, ok := counts[key]; !ok {
+			first[key] = i
+		}
+		counts[key]++
+		keys[i] = key
+	}
+	var deduped = items[:0]
+	for i, item := range items {
+		if first[keys[i]] != i {
+			continue
+		}
+		if a.DedupMerge != nil {
+			item = a.DedupMerge(item, counts[keys[i]])
+		}
+		deduped = append(deduped, item)
+	}
+	return deduped
+}
+
+// handle passes the items to the flush function.
+func (a *Accumulator[T]) handle(items []T) error {
+	if a.MaxConcurrentFlushes > 0 {
+		a.semaphoreOnce.Do(func() {
+			a.semaphore = make(chan struct{}, a.MaxConcurrentFlushes)
+		})
+		a.semaphore <- struct{}{}
+		defer func() { <-a.semaphore }()
+	}
+	if a.FlushErrFunc != nil {
+		if err := a.flushErr(items); err != nil {
+			return err
+		}
+	} else {
 		a.FlushFunc(items)
 	}
+	if a.OnDelivered != nil {
+		a.OnDelivered(items)
+	}
+	return nil
+}
+
+// flushErr passes the items to the FlushErrFunc, through the circuit breaker if it is enabled.
+func (a *Accumulator[T]) flushErr(items []T) error {
+	if a.BreakerThreshold <= 0 {
+		return a.FlushErrFunc(items)
+	}
+	var cooldown = a.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = a.FlushInterval
+	}
+	if !a.breaker.allow(a.clock.Now(), cooldown) {
+		if a.FallbackFunc != nil {
+			a.FallbackFunc(items)
+		}
+		return ErrCircuitOpen
+	}
+	var err = a.FlushErrFunc(items)
+	a.breaker.record(a.clock.Now(), err, a.BreakerThreshold)
+	return err
 }
 
-// Close closes the accumulator.
+// Close flushes the queue and closes the accumulator.
+//
+// Items pushed after the accumulator was closed are dropped, see Stats.
+// Calling Close more than once is a no-op.
 func (a *Accumulator[T]) Close() {
-	a.Flush()
-	close(a.closeChan)
+	a.closeOnce.Do(func() {
+		a.mutex.Lock()
+		a.closed = true
+		a.flushLocked()
+		a.mutex.Unlock()
+		a.ticker.Stop()
+		close(a.closeChan)
+	})
 }
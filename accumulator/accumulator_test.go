@@ -0,0 +1,553 @@
+package accumulator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlushSyncWaitsForFlush(t *testing.T) {
+	var done atomic.Bool
+	var a = NewAccumulator[int](100, time.Hour, nil)
+	defer a.Close()
+	a.FlushErrFunc = func(items []int) error {
+		time.Sleep(20 * time.Millisecond)
+		done.Store(true)
+		return errors.New("flush failed")
+	}
+	a.Push(1)
+
+	var err = a.FlushSync()
+	if !done.Load() {
+		t.Error("FlushSync returned before the flush func completed")
+	}
+	if err == nil || err.Error() != "flush failed" {
+		t.Errorf("FlushSync returned %v, want the error of the flush func", err)
+	}
+}
+
+func TestMaxBatchSize(t *testing.T) {
+	var batches [][]int
+	var a = NewAccumulator(1000, time.Hour, func(items []int) {
+		batches = append(batches, items)
+	})
+	defer a.Close()
+	a.MaxBatchSize = 100
+	for i := 0; i < 250; i++ {
+		a.Push(i)
+	}
+	var order = a.Snapshot()
+	a.Flush()
+
+	if len(batches) != 3 || len(batches[0]) != 100 || len(batches[1]) != 100 || len(batches[2]) != 50 {
+		var sizes []int
+		for _, batch := range batches {
+			sizes = append(sizes, len(batch))
+		}
+		t.Fatalf("flushed batches of %v, want 100, 100 and 50", sizes)
+	}
+	var flushed = append(append(append([]int(nil), batches[0]...), batches[1]...), batches[2]...)
+	for i := range order {
+		if flushed[i] != order[i] {
+			t.Fatalf("item %d is %d, want %d: the order of the queue is not kept", i, flushed[i], order[i])
+		}
+	}
+}
+
+func TestFlushFirstImmediately(t *testing.T) {
+	var batches []int
+	var a, clock = newFakeAccumulator(100, 50*time.Millisecond, func(items []int) {
+		batches = append(batches, len(items))
+	})
+	defer a.Close()
+	a.FlushFirstImmediately = true
+	a.ResetMode = ResetOnPush
+
+	clock.advance(60 * time.Millisecond)
+	a.Push(1)
+	if len(batches) != 1 || batches[0] != 1 {
+		t.Fatalf("flushed batches of %v after an idle push, want [1]", batches)
+	}
+
+	for i := 0; i < 5; i++ {
+		clock.advance(time.Millisecond)
+		a.Push(i)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("flushed batches of %v right after the burst, want it to be batched", batches)
+	}
+	clock.advance(50 * time.Millisecond)
+	if len(batches) != 2 || batches[1] != 5 {
+		t.Errorf("flushed batches of %v, want the burst in a single batch", batches)
+	}
+}
+
+func TestMaxConcurrentFlushes(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var a = NewAccumulator(1000, time.Hour, func(items []int) {
+		var n = running.Add(1)
+		defer running.Add(-1)
+		for {
+			var max = maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	})
+	defer a.Close()
+	a.MaxConcurrentFlushes = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.handle([]int{i})
+		}(i)
+	}
+	wg.Wait()
+	if max := maxRunning.Load(); max > 2 {
+		t.Errorf("%d flushes ran at the same time, want at most 2", max)
+	}
+}
+
+func TestFlushesAreSerialized(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var a = NewAccumulator(1, time.Millisecond, func(items []int) {
+		var n = running.Add(1)
+		defer running.Add(-1)
+		for {
+			var max = maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+	})
+	defer a.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.Push(i)
+		}(i)
+	}
+	wg.Wait()
+	a.Flush()
+	if max := maxRunning.Load(); max != 1 {
+		t.Errorf("%d flushes ran at the same time, want 1", max)
+	}
+}
+
+func TestInitialItemsFlushFirst(t *testing.T) {
+	var flushed []int
+	var a = NewAccumulatorWithInitial([]int{1, 2, 3}, 10, time.Hour, func(items []int) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	a.Push(4)
+	a.Push(5)
+
+	if got := a.Snapshot(); len(got) != 5 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("snapshot is %v, want the initial items first", got)
+	}
+	if got := a.Stats().Queued; got != 5 {
+		t.Errorf("%d items queued, want 5", got)
+	}
+	a.Flush()
+	if len(flushed) != 5 || flushed[0] != 1 || flushed[1] != 2 || flushed[2] != 3 {
+		t.Errorf("flushed %v, want the initial items first", flushed)
+	}
+}
+
+func TestInitialItemsReachFlushSize(t *testing.T) {
+	var flushed []int
+	var a = NewAccumulatorWithInitial([]int{1, 2}, 2, time.Hour, func(items []int) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	if len(flushed) != 2 || flushed[0] != 1 || flushed[1] != 2 {
+		t.Errorf("flushed %v, want the initial items right away", flushed)
+	}
+}
+
+func TestDedupKeepsInitialItem(t *testing.T) {
+	var flushed []string
+	var a = NewAccumulatorWithInitial([]string{"a:initial"}, 10, time.Hour, func(items []string) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	a.Dedup = func(item string) string { return item[:1] }
+	a.Push("a:pushed")
+	a.Push("b:pushed")
+	a.Flush()
+	if len(flushed) != 2 || flushed[0] != "a:initial" {
+		t.Errorf("flushed %v, want the initial item kept for key a", flushed)
+	}
+}
+
+func TestInitialItemsExceedFlushSize(t *testing.T) {
+	var flushed []int
+	var a = NewAccumulatorWithInitial([]int{1, 2, 3, 4, 5}, 3, time.Hour, func(items []int) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	if len(flushed) != 5 {
+		t.Fatalf("flushed %v, want the backlog flushed right away", flushed)
+	}
+	for i, item := range flushed {
+		if item != i+1 {
+			t.Errorf("flushed %v, want the backlog in order", flushed)
+			break
+		}
+	}
+}
+
+func TestDedupCounts(t *testing.T) {
+	var flushed []string
+	var a = NewAccumulator(100, time.Hour, func(items []string) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	a.Dedup = func(item string) string { return strings.SplitN(item, ":", 2)[0] }
+	a.DedupMerge = func(item string, count int) string { return fmt.Sprintf("%s x%d", item, count) }
+	for _, item := range []string{"retry:1", "retry:2", "timeout:1", "retry:3", "done:1", "timeout:2"} {
+		a.Push(item)
+	}
+	a.Flush()
+
+	sort.Strings(flushed)
+	var want = []string{"done:1 x1", "retry:1 x3", "timeout:1 x2"}
+	if strings.Join(flushed, ",") != strings.Join(want, ",") {
+		t.Errorf("flushed %v, want %v", flushed, want)
+	}
+}
+
+func TestOnDelivered(t *testing.T) {
+	var fail = true
+	var delivered [][]int
+	var a = NewAccumulator[int](100, time.Hour, nil)
+	defer a.Close()
+	a.FlushErrFunc = func(items []int) error {
+		if fail {
+			return errors.New("flush failed")
+		}
+		return nil
+	}
+	a.OnDelivered = func(items []int) {
+		delivered = append(delivered, append([]int(nil), items...))
+	}
+
+	a.Push(1)
+	if err := a.FlushSync(); err == nil || len(delivered) != 0 {
+		t.Fatalf("failed flush: got %v, delivered %v", err, delivered)
+	}
+
+	fail = false
+	a.Push(2)
+	a.Push(3)
+	if err := a.FlushSync(); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 1 || len(delivered[0]) != 2 || delivered[0][0] != 3 || delivered[0][1] != 2 {
+		t.Errorf("delivered %v, want the flushed items [3 2]", delivered)
+	}
+}
+
+func TestResetOnFlushSteadyPushes(t *testing.T) {
+	var flushed = make(chan int, 100)
+	var a = NewAccumulator(1000, 50*time.Millisecond, func(items []int) {
+		flushed <- len(items)
+	})
+	defer a.Close()
+	a.ResetMode = ResetOnFlush
+
+	// Push more often than the interval, without ever reaching the flush size.
+	var stop = make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				a.Push(i)
+			}
+		}
+	}()
+
+	select {
+	case n := <-flushed:
+		if n == 0 || n >= 1000 {
+			t.Errorf("flushed %d items, want a partial batch", n)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Error("steady pushes kept the interval from flushing")
+	}
+}
+
+// steadyBatches pushes an item every 2ms for 300ms on a fake clock, with a flush interval of 50ms,
+// and returns the sizes of the batches which were flushed in that time.
+func steadyBatches(mode ResetMode, flushSize int) []int {
+	var batches []int
+	var a, clock = newFakeAccumulator(flushSize, 50*time.Millisecond, func(items []int) {
+		batches = append(batches, len(items))
+	})
+	defer a.Close()
+	a.ResetMode = mode
+	for i := 0; i < 150; i++ {
+		a.Push(0)
+		clock.advance(2 * time.Millisecond)
+	}
+	return batches
+}
+
+func TestResetModes(t *testing.T) {
+	t.Run("below flush size", func(t *testing.T) {
+		// Only the interval flushes, unless it is reset on every push.
+		if got := steadyBatches(NoReset, 1<<20); len(got) < 2 {
+			t.Errorf("NoReset flushed %v, want a flush every interval", got)
+		}
+		if got := steadyBatches(ResetOnFlush, 1<<20); len(got) < 2 {
+			t.Errorf("ResetOnFlush flushed %v, want a flush every interval", got)
+		}
+		if got := steadyBatches(ResetOnPush, 1<<20); len(got) != 0 {
+			t.Errorf("ResetOnPush flushed %v, want no flushes while pushes continue", got)
+		}
+	})
+	t.Run("reaching flush size", func(t *testing.T) {
+		// The flush size is reached about twice per interval, the interval flushes partial batches unless it is reset.
+		var partial = func(batches []int) bool {
+			for _, n := range batches {
+				if n < 10 {
+					return true
+				}
+			}
+			return false
+		}
+		if got := steadyBatches(NoReset, 10); !partial(got) {
+			t.Errorf("NoReset flushed %v, want partial batches from the interval", got)
+		}
+		if got := steadyBatches(ResetOnFlush, 10); len(got) == 0 || partial(got) {
+			t.Errorf("ResetOnFlush flushed %v, want only full batches", got)
+		}
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	var flushed []int
+	var a = NewAccumulator(100, time.Hour, func(items []int) {
+		flushed = append(flushed, items...)
+	})
+	defer a.Close()
+	for i := 1; i <= 4; i++ {
+		a.Push(i)
+	}
+
+	var snapshot = a.Snapshot()
+	if got := a.Stats().Queued; got != 4 {
+		t.Errorf("%d items queued after the snapshot, want 4", got)
+	}
+	// Changing the snapshot does not change the queue.
+	var want = fmt.Sprint(snapshot)
+	snapshot[0] = -1
+	a.Flush()
+	// The queue is a stack, the snapshot is in the order the items are flushed.
+	if got := fmt.Sprint(flushed); got != want || want != "[4 3 2 1]" {
+		t.Errorf("flushed %s, snapshot was %s, want [4 3 2 1]", got, want)
+	}
+	if snapshot = a.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("snapshot after the flush is %v, want it empty", snapshot)
+	}
+}
+
+// Run with -race, Close and Push are called from many goroutines.
+func TestCloseTwice(t *testing.T) {
+	var flushes int
+	var a = NewAccumulator(100, time.Millisecond, func(items []int) {
+		flushes++
+	})
+	a.Push(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.Close()
+		}()
+	}
+	wg.Wait()
+	a.Close()
+	if flushes != 1 {
+		t.Errorf("flushed %d times, want the queue flushed once on close", flushes)
+	}
+}
+
+// Run with -race, items are pushed while the accumulator is closed.
+func TestPushAfterClose(t *testing.T) {
+	var flushed atomic.Int32
+	var a = NewAccumulator(100, time.Millisecond, func(items []int) {
+		flushed.Add(int32(len(items)))
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				a.Push(i)
+			}
+		}(i)
+	}
+	a.Close()
+	wg.Wait()
+	a.Push(1)
+
+	var dropped = a.Stats().Dropped
+	if dropped == 0 {
+		t.Error("the push after Close was not dropped")
+	}
+	if got := int(flushed.Load()) + dropped; got != 101 {
+		t.Errorf("%d items were flushed or dropped, want all 101", got)
+	}
+}
+
+// Run with -race, items are pushed from many goroutines while the worker flushes.
+func TestConcurrentPushesAreFlushedOnce(t *testing.T) {
+	const goroutines, perGoroutine = 50, 200
+	var mutex sync.Mutex
+	var seen = make(map[int]int)
+	var a = NewAccumulator(37, time.Millisecond, func(items []int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, item := range items {
+			seen[item]++
+		}
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				a.Push(g*perGoroutine + i)
+				if i%50 == 0 {
+					a.Flush()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	a.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("flushed %d distinct items, want %d", len(seen), goroutines*perGoroutine)
+	}
+	for item, n := range seen {
+		if n != 1 {
+			t.Errorf("item %d was flushed %d times", item, n)
+		}
+	}
+}
+
+func TestFlushSizeFlushesPromptly(t *testing.T) {
+	var flushed = make(chan []int, 1)
+	var a = NewAccumulator(3, time.Hour, func(items []int) {
+		flushed <- items
+	})
+	defer a.Close()
+	for i := 0; i < 3; i++ {
+		a.Push(i)
+	}
+	// The push which reaches the flush size flushes before it returns, without waiting for the worker.
+	select {
+	case items := <-flushed:
+		if len(items) != 3 {
+			t.Errorf("flushed %v, want 3 items", items)
+		}
+	default:
+		t.Fatal("reaching the flush size did not flush before Push returned")
+	}
+}
+
+func TestFlushIntervalFlushes(t *testing.T) {
+	var flushed = make(chan time.Time, 1)
+	var a = NewAccumulator(1000, 50*time.Millisecond, func(items []int) {
+		flushed <- time.Now()
+	})
+	defer a.Close()
+	var start = time.Now()
+	a.Push(1)
+	select {
+	case at := <-flushed:
+		if elapsed := at.Sub(start); elapsed < 40*time.Millisecond {
+			t.Errorf("flushed after %s, before the interval passed", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the interval did not flush")
+	}
+}
+
+func TestResetAfterPushKeepsFlushing(t *testing.T) {
+	var flushed = make(chan time.Time, 1)
+	var a = NewAccumulator(1000, 100*time.Millisecond, func(items []int) {
+		select {
+		case flushed <- time.Now():
+		default:
+		}
+	})
+	defer a.Close()
+	a.ResetAfterPush = true
+
+	// ResetAfterPush only resets the ticker on flushes, so the interval keeps flushing steady pushes.
+	var start = time.Now()
+	for end := start.Add(250 * time.Millisecond); time.Now().Before(end); {
+		a.Push(0)
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case at := <-flushed:
+		if elapsed := at.Sub(start); elapsed > 200*time.Millisecond {
+			t.Errorf("first flush after %s, want one every interval", elapsed)
+		}
+	default:
+		t.Error("steady pushes kept the interval from flushing")
+	}
+}
+
+func TestIdleAccumulatorDoesNotFlush(t *testing.T) {
+	var calls atomic.Int32
+	var a = NewAccumulator(10, 5*time.Millisecond, func(items []int) {
+		calls.Add(1)
+	})
+	defer a.Close()
+	time.Sleep(50 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("an empty queue was flushed %d times", n)
+	}
+
+	a.Push(1)
+	time.Sleep(50 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("one item was flushed %d times, want once", n)
+	}
+}
+
+func BenchmarkPushFlushSize(b *testing.B) {
+	var a = NewAccumulator(100, time.Hour, func(items []int) {})
+	defer a.Close()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a.Push(i)
+	}
+}
@@ -0,0 +1,120 @@
+package accumulator
+
+import "time"
+
+// Stats holds statistics about the accumulator.
+type Stats struct {
+	// The amount of items in the queue.
+	Queued int
+
+	// The current push rate in items per second, only measured when AdaptiveRate is set.
+	PushRate float64
+
+	// The flush interval currently in effect.
+	//
+	// This is shorter than the FlushInterval when the push rate exceeds the AdaptiveRate.
+	EffectiveInterval time.Duration
+
+	// The amount of items which were dropped, because they were pushed after the accumulator was closed.
+	Dropped int
+
+	// Whether the circuit breaker is open, see BreakerThreshold.
+	CircuitOpen bool
+}
+
+// Stats returns statistics about the accumulator.
+func (a *Accumulator[T]) Stats() Stats {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return Stats{
+		Queued:            a.queued(),
+		PushRate:          a.pushRate(a.clock.Now()),
+		EffectiveInterval: a.interval(),
+		Dropped:           a.dropped,
+		CircuitOpen:       a.breaker.isOpen(),
+	}
+}
+
+// interval returns the flush interval currently in effect.
+func (a *Accumulator[T]) interval() time.Duration {
+	if a.effectiveInterval > 0 {
+		return a.effectiveInterval
+	}
+	return a.FlushInterval
+}
+
+// adaptiveWindow returns the window over which the push rate is measured.
+func (a *Accumulator[T]) adaptiveWindow() time.Duration {
+	if a.AdaptiveWindow > 0 {
+		return a.AdaptiveWindow
+	}
+	return a.FlushInterval
+}
+
+// countPush counts a push for the push rate.
+//
+// The push rate is measured over a sliding window, approximated by
+// the counts of the current and the previous window.
+func (a *Accumulator[T]) countPush(now time.Time) {
+	a.rotateRateWindow(now)
+	a.pushesCurrent++
+}
+
+func (a *Accumulator[T]) rotateRateWindow(now time.Time) {
+	var window = a.adaptiveWindow()
+	var elapsed = now.Sub(a.windowStart)
+	switch {
+	case elapsed >= 2*window:
+		a.pushesPrevious = 0
+		a.pushesCurrent = 0
+		a.windowStart = now
+	case elapsed >= window:
+		a.pushesPrevious = a.pushesCurrent
+		a.pushesCurrent = 0
+		a.windowStart = a.windowStart.Add(window)
+	}
+}
+
+// pushRate returns the push rate in items per second.
+func (a *Accumulator[T]) pushRate(now time.Time) float64 {
+	if a.AdaptiveRate <= 0 {
+		return 0
+	}
+	a.rotateRateWindow(now)
+	var window = a.adaptiveWindow()
+	var weight = 1 - float64(now.Sub(a.windowStart))/float64(window)
+	var pushes = float64(a.pushesPrevious)*weight + float64(a.pushesCurrent)
+	return pushes / window.Seconds()
+}
+
+// adaptInterval shortens the flush interval when the push rate exceeds the AdaptiveRate,
+// proportional to how far it is exceeded, and restores it when the rate drops.
+//
+// The mutex must be held.
+func (a *Accumulator[T]) adaptInterval(now time.Time) {
+	if a.AdaptiveRate <= 0 {
+		return
+	}
+	var interval = a.FlushInterval
+	if rate := a.pushRate(now); rate > a.AdaptiveRate {
+		interval = time.Duration(float64(a.FlushInterval) * a.AdaptiveRate / rate)
+		var minInterval = a.AdaptiveMinInterval
+		if minInterval <= 0 {
+			minInterval = a.FlushInterval / 10
+		}
+		if interval < minInterval {
+			interval = minInterval
+		}
+	}
+	// Only reset the ticker on significant changes,
+	// resetting it on every push would keep it from ever firing.
+	var current = a.interval()
+	var diff = interval - current
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > current/5 || (interval == a.FlushInterval && current != a.FlushInterval) {
+		a.effectiveInterval = interval
+		a.ticker.Reset(interval)
+	}
+}
@@ -0,0 +1,44 @@
+package accumulator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveInterval(t *testing.T) {
+	var mutex sync.Mutex
+	var flushes int
+	var a = NewAccumulator(1<<20, 200*time.Millisecond, func(items []int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		flushes++
+	})
+	defer a.Close()
+	a.AdaptiveRate = 100
+
+	if got := a.Stats().EffectiveInterval; got != 200*time.Millisecond {
+		t.Errorf("effective interval is %s before any push, want the base interval", got)
+	}
+
+	// Push well above the adaptive rate for longer than a single base interval.
+	var end = time.Now().Add(300 * time.Millisecond)
+	for i := 0; time.Now().Before(end); i++ {
+		a.Push(i)
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	var stats = a.Stats()
+	if stats.PushRate <= a.AdaptiveRate {
+		t.Errorf("push rate is %.0f, want above %.0f", stats.PushRate, a.AdaptiveRate)
+	}
+	if stats.EffectiveInterval >= a.FlushInterval {
+		t.Errorf("effective interval is %s, want shorter than %s", stats.EffectiveInterval, a.FlushInterval)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	// At the base interval, the queue is flushed once in this time.
+	if flushes < 3 {
+		t.Errorf("flushed %d times, want more often than the base interval", flushes)
+	}
+}
@@ -0,0 +1,67 @@
+package accumulator
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for flushes which were not attempted, because the circuit breaker is open.
+var ErrCircuitOpen = errors.New("accumulator: circuit breaker is open")
+
+// breaker is a circuit breaker around the FlushErrFunc.
+//
+// It opens after a number of consecutive failed flushes, after which flushes fail fast.
+// Once the cooldown has passed, a single flush is attempted, which closes the circuit if it succeeds.
+type breaker struct {
+	mutex *sync.Mutex
+
+	// The amount of consecutive failed flushes.
+	failures int
+
+	// Whether the circuit is open, and the time of the last attempt while open.
+	open     bool
+	openedAt time.Time
+}
+
+func newBreaker() *breaker {
+	return &breaker{mutex: &sync.Mutex{}}
+}
+
+// allow reports whether a flush may be attempted.
+func (b *breaker) allow(now time.Time, cooldown time.Duration) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.open {
+		return true
+	}
+	if now.Sub(b.openedAt) < cooldown {
+		return false
+	}
+	// Half-open, other flushes fail fast until this one completes.
+	b.openedAt = now
+	return true
+}
+
+// record records the result of a flush.
+func (b *breaker) record(now time.Time, err error, threshold int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err == nil {
+		b.failures = 0
+		b.open = false
+		return
+	}
+	b.failures++
+	if b.open || b.failures >= threshold {
+		b.open = true
+		b.openedAt = now
+	}
+}
+
+// isOpen reports whether the circuit is open.
+func (b *breaker) isOpen() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.open
+}
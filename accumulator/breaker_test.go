@@ -0,0 +1,78 @@
+package accumulator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var fail = true
+	var attempts int
+	var fallback []int
+	var a = NewAccumulator[int](100, time.Hour, nil)
+	defer a.Close()
+	a.FlushErrFunc = func(items []int) error {
+		attempts++
+		if fail {
+			return errors.New("sink is down")
+		}
+		return nil
+	}
+	a.BreakerThreshold = 3
+	a.BreakerCooldown = 20 * time.Millisecond
+	a.FallbackFunc = func(items []int) {
+		fallback = append(fallback, items...)
+	}
+
+	for i := 0; i < 3; i++ {
+		a.Push(i)
+		if err := a.FlushSync(); err == nil || err == ErrCircuitOpen {
+			t.Fatalf("flush %d returned %v, want the error of the sink", i, err)
+		}
+	}
+	if !a.Stats().CircuitOpen {
+		t.Fatal("circuit is not open after 3 failed flushes")
+	}
+
+	a.Push(3)
+	if err := a.FlushSync(); err != ErrCircuitOpen {
+		t.Errorf("flush returned %v, want ErrCircuitOpen", err)
+	}
+	if attempts != 3 || len(fallback) != 1 || fallback[0] != 3 {
+		t.Errorf("%d attempts with fallback %v, want the open circuit to fail fast to the fallback", attempts, fallback)
+	}
+
+	// After the cooldown, a successful half-open attempt closes the circuit.
+	time.Sleep(30 * time.Millisecond)
+	fail = false
+	a.Push(4)
+	if err := a.FlushSync(); err != nil {
+		t.Errorf("half-open flush returned %v", err)
+	}
+	if attempts != 4 || a.Stats().CircuitOpen {
+		t.Errorf("%d attempts, open %t, want the circuit closed after the half-open attempt", attempts, a.Stats().CircuitOpen)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	var b = newBreaker()
+	var now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		b.record(now, errors.New("failed"), 2)
+	}
+	if b.allow(now.Add(time.Second), time.Minute) {
+		t.Error("flush allowed during the cooldown")
+	}
+	now = now.Add(time.Minute)
+	if !b.allow(now, time.Minute) {
+		t.Fatal("half-open flush not allowed after the cooldown")
+	}
+	if b.allow(now, time.Minute) {
+		t.Error("a second flush is allowed while half-open")
+	}
+	b.record(now, errors.New("failed"), 2)
+	if !b.isOpen() || b.allow(now.Add(time.Second), time.Minute) {
+		t.Error("failed half-open flush did not reopen the circuit")
+	}
+}
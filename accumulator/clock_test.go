@@ -0,0 +1,75 @@
+package accumulator
+
+import "time"
+
+// fakeClock is a clock which only moves when advanced, and runs the ticks of its tickers on the way.
+//
+// It is only used from the goroutine of the test, the ticks run on that goroutine instead of the worker.
+type fakeClock struct {
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	var t = &fakeTicker{clock: c, interval: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// advance moves the clock forward by d, and runs the ticks which are due on the way in order,
+// like a time.Ticker which is read without delay.
+func (c *fakeClock) advance(d time.Duration) {
+	var end = c.now.Add(d)
+	for {
+		var due *fakeTicker
+		for _, t := range c.tickers {
+			if !t.stopped && !t.next.After(end) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			break
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.interval)
+		if due.tick != nil {
+			due.tick()
+		}
+	}
+	c.now = end
+}
+
+// fakeTicker is a ticker of a fakeClock, which calls tick instead of sending on its channel.
+type fakeTicker struct {
+	clock    *fakeClock
+	interval time.Duration
+	next     time.Time
+	stopped  bool
+	tick     func()
+}
+
+// C returns a nil channel, the ticks are run by fakeClock.advance.
+func (t *fakeTicker) C() <-chan time.Time {
+	return nil
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.interval = d
+	t.next = t.clock.now.Add(d)
+}
+
+func (t *fakeTicker) Stop() {
+	t.stopped = true
+}
+
+// newFakeAccumulator creates an accumulator like NewAccumulator, of which the time and flush interval are driven by the returned clock.
+func newFakeAccumulator[T any](flushSize int, flushInterval time.Duration, flushFunc func([]T)) (*Accumulator[T], *fakeClock) {
+	var c = &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	var a = newAccumulator(flushSize, flushInterval, flushFunc, c)
+	a.ticker.(*fakeTicker).tick = a.tick
+	return a, c
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// AutoFlushWriter is a buffered writer which is flushed periodically.
+//
+// This gives the throughput of buffering, without keeping data unflushed for long when the program is idle.
+//
+// It is safe for concurrent use.
+type AutoFlushWriter struct {
+	// The buffered writer, and the writer it writes to.
+	buf *bufio.Writer
+	w   io.Writer
+
+	// The ticker which is used to flush the buffer.
+	ticker *time.Ticker
+
+	// The mutex used to lock the buffer.
+	mutex *sync.Mutex
+
+	// closeChan is a channel which is closed when the writer is closed.
+	closeChan chan struct{}
+
+	// closeOnce makes sure the writer is only closed once.
+	closeOnce *sync.Once
+}
+
+// NewAutoFlushWriter creates a new writer which buffers up to size bytes, and flushes to w at least every interval.
+func NewAutoFlushWriter(w io.Writer, size int, interval time.Duration) *AutoFlushWriter {
+	var a = &AutoFlushWriter{
+		buf:       bufio.NewWriterSize(w, size),
+		w:         w,
+		ticker:    time.NewTicker(interval),
+		mutex:     &sync.Mutex{},
+		closeChan: make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+	go a.worker()
+	return a
+}
+
+func (a *AutoFlushWriter) worker() {
+	for {
+		select {
+		case <-a.closeChan:
+			return
+		case <-a.ticker.C:
+			a.Flush()
+		}
+	}
+}
+
+// Write writes p to the buffer.
+//
+// If the buffer is full, it is flushed to the underlying writer.
+func (a *AutoFlushWriter) Write(p []byte) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.buf.Write(p)
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (a *AutoFlushWriter) Flush() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.buf.Flush()
+}
+
+// Unwrap returns the underlying writer.
+func (a *AutoFlushWriter) Unwrap() io.Writer {
+	return a.w
+}
+
+// Close flushes the buffer and stops the periodic flushing.
+//
+// The underlying writer is not closed.
+func (a *AutoFlushWriter) Close() error {
+	a.closeOnce.Do(func() {
+		a.ticker.Stop()
+		close(a.closeChan)
+	})
+	return a.Flush()
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoFlushWriterInterval(t *testing.T) {
+	var buf = &syncBuffer{}
+	var w = NewAutoFlushWriter(buf, 4096, 10*time.Millisecond)
+	defer w.Close()
+
+	w.Write([]byte("hello\n"))
+	if got := buf.String(); got != "" {
+		t.Fatalf("expected the data to be buffered, got %q", got)
+	}
+
+	var deadline = time.Now().Add(time.Second)
+	for buf.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := buf.String(); got != "hello\n" {
+		t.Errorf("expected the data to be flushed after the interval, got %q", got)
+	}
+}
+
+func TestAutoFlushWriterClose(t *testing.T) {
+	var buf = &syncBuffer{}
+	var w = NewAutoFlushWriter(buf, 4096, time.Hour)
+	w.Write([]byte("hello\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("closing twice: %v", err)
+	}
+	if got := buf.String(); got != "hello\n" {
+		t.Errorf("expected the data to be flushed on close, got %q", got)
+	}
+}
+
+// Run with -race, the logger writes while the ticker flushes.
+func TestAutoFlushWriterConcurrent(t *testing.T) {
+	restoreGlobals(t)
+	var buf = &syncBuffer{}
+	var w = NewAutoFlushWriter(buf, 64, time.Millisecond)
+	var l = NewLogger(INFO, w)
+	var done = make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			for j := 0; j < 20; j++ {
+				l.Info("message")
+			}
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+	w.Close()
+	if got := strings.Count(buf.String(), "message\n"); got != 200 {
+		t.Errorf("expected 200 lines, got %d", got)
+	}
+}
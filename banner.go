@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+)
+
+// Banner writes a boxed banner with the name, version and build commit of the program,
+// and the Go version it was built with, at loglevel info.
+//
+// This is meant to be written once, when the program starts.
+// If the logger has a Formatter, the banner is written as a line with the name as message, and the rest as fields.
+func (l *Logger) Banner(name, version, commit string) {
+	if !l.enabled(INFO) {
+		return
+	}
+	if l.Formatter != nil {
+		l.logEntry(&LogEntry{
+			Time:    l.now(),
+			Level:   INFO,
+			Message: name,
+			Fields:  NewFields("version", version, "commit", commit, "go", runtime.Version()),
+		})
+		return
+	}
+	l.writeLine(l.now(), INFO, terminateLines(banner(l.Colorized, name, version, commit), l.LineTerminator), nil)
+}
+
+// A row of the banner, with a dimmed label.
+type bannerRow struct {
+	label string
+	value string
+	color []string
+}
+
+// banner returns the lines of the banner.
+func banner(colorized bool, name, version, commit string) string {
+	var rows = []bannerRow{
+		{value: name, color: []string{Bold, ColorLevelInfo}},
+		{label: "version", value: version, color: []string{Cyan}},
+		{label: "commit", value: commit, color: []string{Cyan}},
+		{label: "go", value: runtime.Version(), color: []string{Cyan}},
+	}
+
+	var maxLabel, maxWidth int
+	for _, row := range rows {
+		if len(row.label) > maxLabel {
+			maxLabel = len(row.label)
+		}
+	}
+	for _, row := range rows {
+		var width = VisibleWidth(row.value)
+		if row.label != "" {
+			width += maxLabel + 1
+		}
+		if width > maxWidth {
+			maxWidth = width
+		}
+	}
+
+	var b = &strings.Builder{}
+	var border = "+" + strings.Repeat("-", maxWidth+2) + "+"
+	writeIfColorized(b, colorized, border, DimGrey)
+	b.WriteString("\n")
+	for _, row := range rows {
+		writeIfColorized(b, colorized, "| ", DimGrey)
+		var width = VisibleWidth(row.value)
+		if row.label != "" {
+			writeIfColorized(b, colorized, row.label+strings.Repeat(" ", maxLabel-len(row.label)+1), DimGrey)
+			width += maxLabel + 1
+		}
+		writeIfColorized(b, colorized, row.value, row.color...)
+		b.WriteString(strings.Repeat(" ", maxWidth-width))
+		writeIfColorized(b, colorized, " |", DimGrey)
+		b.WriteString("\n")
+	}
+	writeIfColorized(b, colorized, border, DimGrey)
+	b.WriteString("\n")
+	return b.String()
+}
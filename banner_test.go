@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBanner(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Banner("app", "v1.2.3", "abcdef0")
+	var out = buf.String()
+	for _, value := range []string{"app", "v1.2.3", "abcdef0", runtime.Version()} {
+		if !strings.Contains(out, value) {
+			t.Errorf("banner does not contain %q:\n%s", value, out)
+		}
+	}
+	// All lines of the box are equally wide.
+	var lines = strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for _, line := range lines {
+		if VisibleWidth(line) != VisibleWidth(lines[0]) {
+			t.Errorf("banner is not aligned:\n%s", out)
+			break
+		}
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.Banner("app", "v1.2.3", "abcdef0")
+	if !strings.Contains(buf.String(), Colorize("app", Bold, ColorLevelInfo)) ||
+		!strings.Contains(buf.String(), Colorize("v1.2.3", Cyan)) {
+		t.Errorf("banner is not styled: %q", buf.String())
+	}
+	if DeColorize(buf.String()) != out {
+		t.Errorf("colorized banner differs from the uncolored banner:\n%s", DeColorize(buf.String()))
+	}
+
+	buf.Reset()
+	l.Loglevel = WARNING
+	l.Banner("app", "v1.2.3", "abcdef0")
+	if buf.Len() != 0 {
+		t.Errorf("banner was written above loglevel info:\n%s", buf.String())
+	}
+}
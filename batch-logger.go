@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/Nigel2392/request-logger/accumulator"
@@ -24,12 +25,22 @@ type BatchLogger struct {
 	// Colorize is a flag which determines whether the log entry is written colorized.
 	Colorize bool
 
+	// The settings for rendering the log entries, such as the LineTerminator.
+	RenderOptions
+
+	// Clock returns the current time, used as the time of new log entries, defaults to time.Now.
+	Clock func() time.Time
+
 	// Handler is a function which determines how the log entry is handled.
 	Handler func(entries []*LogEntry, stdout io.Writer)
 
 	// File is the file to write to.
 	File io.Writer
 
+	// JoinWrites writes all entries of a batch to the file with a single call to Write,
+	// instead of a call per entry. This reduces the amount of system calls for file writers.
+	JoinWrites bool
+
 	// The batcher which is used to batch the log entries.
 	batcher *accumulator.Accumulator[*LogEntry]
 }
@@ -56,6 +67,10 @@ func (l *BatchLogger) LogLevel() request.LogLevel {
 
 // Critical logs a critical message.
 func (l *BatchLogger) Critical(e error) {
+	if isBenign(e) {
+		l.push(&LogEntry{Time: l.now(), Level: CRITICAL, Message: e.Error()})
+		return
+	}
 	l.log(CRITICAL, e.Error())
 }
 
@@ -66,6 +81,10 @@ func (l *BatchLogger) Criticalf(format string, args ...any) {
 
 // Write an error message, loglevel error
 func (l *BatchLogger) Error(args ...any) {
+	if benignArgs(args) {
+		l.push(&LogEntry{Time: l.now(), Level: ERROR, Message: fmt.Sprint(args...)})
+		return
+	}
 	l.log(ERROR, fmt.Sprint(args...))
 }
 
@@ -126,20 +145,38 @@ func (l *BatchLogger) Testf(format string, args ...any) {
 
 // Write a message instantly with the given loglevel.
 func (l *BatchLogger) Now(loglevel Loglevel, format string, args ...any) {
-	if l.Loglevel < loglevel {
+	if !allowLevel(l.Loglevel, loglevel) {
 		return
 	}
 	var entry = NewLogEntry(loglevel, fmt.Sprintf(format, args...), 8, 1)
+	entry.Time = l.now()
 	l.handle([]*LogEntry{entry})
 }
 
 func (l *BatchLogger) log(loglevel Loglevel, message string) {
-	if l.Loglevel < loglevel {
+	if !allowLevel(l.Loglevel, loglevel) {
 		return
 	}
 
 	var entry = NewLogEntry(loglevel, message, 8, 1)
+	entry.Time = l.now()
+
+	l.batcher.Push(entry)
+}
+
+// now returns the current time from the Clock of the logger.
+func (l *BatchLogger) now() time.Time {
+	if l.Clock == nil {
+		return time.Now()
+	}
+	return l.Clock()
+}
 
+// push adds an entry without a stacktrace to the batch.
+func (l *BatchLogger) push(entry *LogEntry) {
+	if !allowLevel(l.Loglevel, entry.Level) {
+		return
+	}
 	l.batcher.Push(entry)
 }
 
@@ -148,16 +185,33 @@ func (l *BatchLogger) handle(entries []*LogEntry) {
 		l.Handler(entries, l.File)
 		return
 	}
+	if l.JoinWrites {
+		l.writeJoined(entries)
+		return
+	}
 	for _, entry := range entries {
 		l.write(entry)
 	}
 }
 
+// writeJoined writes the entries to the file in one piece.
+func (l *BatchLogger) writeJoined(entries []*LogEntry) error {
+	if l.File == nil {
+		return nil
+	}
+	var b = &strings.Builder{}
+	for _, entry := range entries {
+		b.WriteString(entry.AsStringWith(l.Prefix, l.Colorize, l.RenderOptions))
+	}
+	var _, err = io.WriteString(l.File, b.String())
+	return err
+}
+
 // log logs a log entry.
 func (l *BatchLogger) write(entry *LogEntry) error {
 	// Write to file.
 	if l.File != nil {
-		var _, err = l.File.Write([]byte(entry.AsString(l.Prefix, l.Colorize)))
+		var _, err = l.File.Write([]byte(entry.AsStringWith(l.Prefix, l.Colorize, l.RenderOptions)))
 		if err != nil {
 			return err
 		}
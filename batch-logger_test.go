@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingWriter counts the calls to Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func (w *countingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func testEntries(n int) []*LogEntry {
+	var entries = make([]*LogEntry, n)
+	for i := range entries {
+		entries[i] = &LogEntry{Time: testTime, Level: INFO, Message: fmt.Sprintf("entry %d", i)}
+	}
+	return entries
+}
+
+func TestBatchLoggerJoinWrites(t *testing.T) {
+	restoreGlobals(t)
+	var entries = testEntries(5)
+	var perLine, joined = &countingWriter{}, &countingWriter{}
+	(&BatchLogger{File: perLine}).handle(entries)
+	(&BatchLogger{File: joined, JoinWrites: true}).handle(entries)
+
+	if perLine.writes != 5 || joined.writes != 1 {
+		t.Errorf("wrote %d times per line and %d times joined, want 5 and 1", perLine.writes, joined.writes)
+	}
+	if joined.String() != perLine.String() {
+		t.Errorf("joined output differs:\n%s\nwant:\n%s", joined.String(), perLine.String())
+	}
+	var lines = strings.Split(strings.TrimSuffix(joined.String(), "\n"), "\n")
+	for i, line := range lines {
+		if !strings.HasSuffix(line, fmt.Sprintf("entry %d", i)) {
+			t.Errorf("line %d is %q, want the entries in order", i, line)
+		}
+	}
+}
+
+func TestBatchLoggerFlushJoined(t *testing.T) {
+	restoreGlobals(t)
+	var w = &countingWriter{}
+	var l = NewBatchLogger(DEBUG, 100, time.Hour, w)
+	l.JoinWrites = true
+	for i := 0; i < 5; i++ {
+		l.Infof("message %d", i)
+	}
+	l.batcher.Flush()
+	if w.writes != 1 {
+		t.Errorf("batch was written in %d calls, want 1", w.writes)
+	}
+	for i := 0; i < 5; i++ {
+		if !strings.Contains(w.String(), fmt.Sprintf("message %d", i)) {
+			t.Errorf("message %d is missing:\n%s", i, w.String())
+		}
+	}
+}
+
+func benchmarkBatchLogger(b *testing.B, join bool) {
+	var f, err = os.Create(filepath.Join(b.TempDir(), "log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	var l = &BatchLogger{File: f, JoinWrites: join}
+	var entries = testEntries(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.handle(entries)
+	}
+}
+
+func BenchmarkBatchLoggerPerLine(b *testing.B) {
+	benchmarkBatchLogger(b, false)
+}
+
+func BenchmarkBatchLoggerJoined(b *testing.B) {
+	benchmarkBatchLogger(b, true)
+}
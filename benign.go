@@ -0,0 +1,30 @@
+package logger
+
+import "errors"
+
+// A benign error is an expected error, such as a validation error.
+//
+// No stacktrace is captured or written for benign errors, even at the ERROR loglevel.
+type benign interface {
+	Benign() bool
+}
+
+// isBenign reports whether the error, or an error it wraps, is benign.
+func isBenign(err error) bool {
+	var b benign
+	return errors.As(err, &b) && b.Benign()
+}
+
+// benignArgs reports whether the arguments contain errors, which are all benign.
+func benignArgs(args []any) bool {
+	var found bool
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if !isBenign(err) {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type validationError struct{ field string }
+
+func (e *validationError) Error() string { return e.field + " is required" }
+func (e *validationError) Benign() bool  { return true }
+
+func TestBenignError(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Error(&validationError{"name"})
+	if strings.Contains(buf.String(), "Stacktrace:") {
+		t.Errorf("benign error has a stacktrace:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[ERROR] name is required") {
+		t.Errorf("benign error was not logged:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	l.Error(fmt.Errorf("wrapped: %w", &validationError{"name"}))
+	if strings.Contains(buf.String(), "Stacktrace:") {
+		t.Errorf("wrapped benign error has a stacktrace:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	l.Error(errors.New("unexpected"))
+	if !strings.Contains(buf.String(), "Stacktrace:") {
+		t.Errorf("error has no stacktrace:\n%s", buf.String())
+	}
+}
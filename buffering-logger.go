@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Nigel2392/router/v3/request"
+)
+
+// BufferingLogger is a logger which keeps its messages in memory,
+// until they are either flushed to the parent logger or discarded.
+//
+// This is useful when handling requests, where the detailed
+// debug and info messages are only wanted if the request ends in an error.
+type BufferingLogger struct {
+	// The logger the buffered messages are replayed to.
+	//
+	// The parent's loglevel, prefix and writer are used.
+	Parent *Logger
+
+	// The buffered log entries.
+	entries []*LogEntry
+
+	// The mutex used to lock the entries.
+	mutex *sync.Mutex
+}
+
+// NewBufferingLogger creates a new BufferingLogger which replays to the parent logger.
+func NewBufferingLogger(parent *Logger) *BufferingLogger {
+	return &BufferingLogger{
+		Parent: parent,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Loglevel returns the loglevel of the parent logger.
+func (l *BufferingLogger) LogLevel() request.LogLevel {
+	return l.Parent.LogLevel()
+}
+
+// Critical buffers a critical message, including the stacktrace of the call.
+func (l *BufferingLogger) Critical(err error) {
+	if isBenign(err) {
+		l.logLine(CRITICAL, err.Error())
+		return
+	}
+	l.logTrace(CRITICAL, err, 1)
+}
+
+// Criticalf buffers a critical message with a format, including the stacktrace of the call.
+func (l *BufferingLogger) Criticalf(format string, args ...any) {
+	l.logTrace(CRITICAL, fmt.Errorf(format, args...), 1)
+}
+
+// Buffer an error message, loglevel error, including the stacktrace of the call.
+func (l *BufferingLogger) Error(args ...any) {
+	if benignArgs(args) {
+		l.logLine(ERROR, fmt.Sprint(args...))
+		return
+	}
+	l.logTrace(ERROR, errors.New(fmt.Sprint(args...)), 1)
+}
+
+// Buffer an error message, loglevel error, including the stacktrace of the call.
+func (l *BufferingLogger) Errorf(format string, args ...any) {
+	l.logTrace(ERROR, fmt.Errorf(format, args...), 1)
+}
+
+// Buffer a warning message, loglevel warning
+func (l *BufferingLogger) Warning(args ...any) {
+	l.logLine(WARNING, fmt.Sprint(args...))
+}
+
+// Buffer a warning message, loglevel warning
+func (l *BufferingLogger) Warningf(format string, args ...any) {
+	l.log(WARNING, fmt.Sprintf(format, args...))
+}
+
+// Buffer an info message, loglevel info
+func (l *BufferingLogger) Info(args ...any) {
+	l.logLine(INFO, fmt.Sprint(args...))
+}
+
+// Buffer an info message, loglevel info
+func (l *BufferingLogger) Infof(format string, args ...any) {
+	l.log(INFO, fmt.Sprintf(format, args...))
+}
+
+// Buffer a debug message, loglevel debug
+func (l *BufferingLogger) Debug(args ...any) {
+	l.logLine(DEBUG, fmt.Sprint(args...))
+}
+
+// Buffer a debug message, loglevel debug
+func (l *BufferingLogger) Debugf(format string, args ...any) {
+	l.log(DEBUG, fmt.Sprintf(format, args...))
+}
+
+// Buffer a test message, loglevel test
+func (l *BufferingLogger) Test(args ...any) {
+	l.logLine(TEST, fmt.Sprint(args...))
+}
+
+// Buffer a test message, loglevel test
+func (l *BufferingLogger) Testf(format string, args ...any) {
+	l.log(TEST, fmt.Sprintf(format, args...))
+}
+
+// Flush replays all buffered messages to the parent logger, and empties the buffer.
+//
+// The messages keep the time at which they were buffered.
+func (l *BufferingLogger) Flush() {
+	for _, entry := range l.drain() {
+		if len(entry.Stacktrace) > 0 {
+			l.Parent.WriteEntry(entry)
+			continue
+		}
+		l.Parent.logAt(entry.Time, entry.Level, entry.Message)
+	}
+}
+
+// Discard empties the buffer without writing any of the messages.
+func (l *BufferingLogger) Discard() {
+	l.drain()
+}
+
+func (l *BufferingLogger) drain() []*LogEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var entries = l.entries
+	l.entries = nil
+	return entries
+}
+
+func (l *BufferingLogger) logLine(level Loglevel, msg string) {
+	l.log(level, lineOf(msg))
+}
+
+func (l *BufferingLogger) log(level Loglevel, msg string) {
+	if !l.Parent.enabled(level) {
+		return
+	}
+	l.push(&LogEntry{
+		Time:    l.Parent.now(),
+		Level:   level,
+		Message: msg,
+	})
+}
+
+// logTrace buffers the error with the stacktrace of the call, up to the StacktraceDepth of the parent.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logTrace.
+func (l *BufferingLogger) logTrace(level Loglevel, err error, skip int) {
+	if !l.Parent.enabled(level) {
+		return
+	}
+	l.push(l.Parent.traceEntry(level, err, skip+1))
+}
+
+func (l *BufferingLogger) push(entry *LogEntry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.entries = append(l.entries, entry)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBufferingLoggerFlush(t *testing.T) {
+	var parent, buf = newTestLogger(t, DEBUG)
+	var l = NewBufferingLogger(parent)
+	l.Debug("first")
+	l.Infof("second %d", 2)
+	l.Warning("third")
+	if buf.Len() != 0 {
+		t.Fatalf("lines were written before Flush:\n%s", buf.String())
+	}
+
+	l.Flush()
+	var out = buf.String()
+	var first, second, third = strings.Index(out, "first"), strings.Index(out, "second 2"), strings.Index(out, "third")
+	if first < 0 || second < first || third < second {
+		t.Errorf("flushed lines are missing or out of order:\n%s", out)
+	}
+
+	buf.Reset()
+	l.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("lines were flushed twice:\n%s", buf.String())
+	}
+}
+
+func TestBufferingLoggerDiscard(t *testing.T) {
+	var parent, buf = newTestLogger(t, DEBUG)
+	var l = NewBufferingLogger(parent)
+	l.Info("dropped")
+	l.Error("dropped too")
+	l.Discard()
+	l.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("discarded lines were written:\n%s", buf.String())
+	}
+}
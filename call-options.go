@@ -0,0 +1,57 @@
+package logger
+
+import "errors"
+
+// A CallOption changes the behaviour of a single log call.
+//
+// Call options are passed to Logger.LogOpts.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	// Write the stacktrace of the call after the message.
+	stack bool
+
+	// Fields which are added to the lines of the call.
+	fields Fields
+}
+
+// WithStack writes the stacktrace of the call after the message.
+func WithStack() CallOption {
+	return func(o *callOptions) {
+		o.stack = true
+	}
+}
+
+// Field adds a key-value pair to the lines of the call.
+func Field(key string, value any) CallOption {
+	return func(o *callOptions) {
+		o.fields = o.fields.With(key, value)
+	}
+}
+
+// LogOpts writes a message with the given loglevel, changed by the call options.
+//
+// This allows overriding the behaviour of the logger for a single call:
+//
+//	l.LogOpts(logger.INFO, "message", logger.WithStack(), logger.Field("key", "value"))
+func (l *Logger) LogOpts(level Loglevel, msg string, opts ...CallOption) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var o = &callOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var logger = l
+	if len(o.fields) > 0 {
+		logger = l.WithFields(o.fields)
+	}
+
+	if o.stack {
+		logger.logEntry(logger.traceEntry(level, errors.New(msg), 1))
+		return
+	}
+	logger.logLine(level, msg)
+}
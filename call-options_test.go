@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogOptsWithStack(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.LogOpts(INFO, "plain")
+	l.LogOpts(INFO, "with stack", WithStack())
+	l.LogOpts(INFO, "plain again")
+
+	var out = buf.String()
+	if n := strings.Count(out, "Stacktrace:"); n != 1 {
+		t.Errorf("wrote %d stacktraces, want 1:\n%s", n, out)
+	}
+	var stack = strings.Index(out, "Stacktrace:")
+	if stack < strings.Index(out, "with stack") || stack > strings.Index(out, "plain again") {
+		t.Errorf("stacktrace is not under the call with WithStack:\n%s", out)
+	}
+	if !hasTestTrace(out, "call-options_test.go") {
+		t.Errorf("stacktrace does not end at the call:\n%s", out)
+	}
+}
+
+func TestLogOptsField(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.LogOpts(WARNING, "tagged", Field("k", "v"))
+	l.LogOpts(WARNING, "untagged")
+
+	var lines = strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "tagged k=v") || strings.Contains(lines[1], "k=v") {
+		t.Errorf("field is not only on the tagged line:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+// The caller of the logger is looked up outside of the logger package, so these tests are in their own package.
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	logger "github.com/Nigel2392/request-logger"
+)
+
+type handler struct {
+	l *logger.Logger
+}
+
+func (h *handler) serve() {
+	h.l.Info("serving")
+}
+
+func logFromNamedFunction(l *logger.Logger) {
+	l.Info("named")
+}
+
+// The package-level functions wrap the default logger, their frames are skipped too.
+func logWithDefault() {
+	logger.Info("default")
+}
+
+func TestIncludeCallerFunc(t *testing.T) {
+	var l = logger.NewLogger(logger.DEBUG, &bytes.Buffer{})
+	l.IncludeCallerFunc = true
+	var funcs []any
+	l.OnEntry = func(entry *logger.LogEntry) {
+		var name, _ = entry.Fields.Get("caller_func")
+		funcs = append(funcs, name)
+	}
+
+	logFromNamedFunction(l)
+	(&handler{l}).serve()
+	var previous = logger.Default()
+	defer logger.SetDefault(previous)
+	logger.SetDefault(l)
+	logWithDefault()
+
+	var want = []any{
+		"github.com/Nigel2392/request-logger_test.logFromNamedFunction",
+		"github.com/Nigel2392/request-logger_test.(*handler).serve",
+		"github.com/Nigel2392/request-logger_test.logWithDefault",
+	}
+	if len(funcs) != len(want) {
+		t.Fatalf("got caller funcs %q, want %q", funcs, want)
+	}
+	for i := range want {
+		if funcs[i] != want[i] {
+			t.Errorf("got caller func %q, want %q", funcs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import "sync"
+
+// captureTracker collects the lines written while Capture is running.
+type captureTracker struct {
+	mutex *sync.Mutex
+
+	// The lines of the captures which are running, nested captures all receive the lines.
+	active []*[]string
+}
+
+func newCaptureTracker() *captureTracker {
+	return &captureTracker{mutex: &sync.Mutex{}}
+}
+
+// start starts a capture, the returned function ends it and returns the captured lines.
+func (c *captureTracker) start() (end func() []string) {
+	var lines = &[]string{}
+	c.mutex.Lock()
+	c.active = append(c.active, lines)
+	c.mutex.Unlock()
+	return func() []string {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for i, active := range c.active {
+			if active == lines {
+				c.active = append(c.active[:i], c.active[i+1:]...)
+				break
+			}
+		}
+		return *lines
+	}
+}
+
+// add adds a written line to the running captures.
+func (c *captureTracker) add(line string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, lines := range c.active {
+		*lines = append(*lines, line)
+	}
+}
+
+// Capture runs fn, and returns the lines the logger wrote while it was running.
+//
+// The lines are still written as usual. Only lines of this logger and the loggers derived from it are captured,
+// including lines written by other goroutines while fn is running.
+func (l *Logger) Capture(fn func()) []string {
+	var end = l.ownScope().captures.start()
+	defer end()
+	fn()
+	return end()
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCaptureScope(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var child = l.WithFields(NewFields("request", 1))
+
+	var lines = child.Capture(func() {
+		l.Info("parent line")
+		child.Info("child line")
+	})
+	if len(lines) != 1 || !strings.Contains(lines[0], "child line") {
+		t.Errorf("child captured %q, want only its own line", lines)
+	}
+
+	lines = l.Capture(func() {
+		l.Info("parent line")
+		child.Info("child line")
+	})
+	if len(lines) != 2 {
+		t.Errorf("parent captured %q, want its own line and the line of the child", lines)
+	}
+}
+
+func TestCaptureAllWritePaths(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var lines = l.Capture(func() {
+		l.Banner("app", "v1.0.0", "abcdef")
+		l.PrintLegend()
+		l.Progress("upload", 1)
+		var g = l.Group("group")
+		g.Info("in group")
+		g.End()
+	})
+	var out = strings.Join(lines, "")
+	for _, want := range []string{"app", "CRITICAL", "upload", "Group: group", "in group"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("capture is missing %q:\n%s", want, out)
+		}
+	}
+}
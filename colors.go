@@ -1,11 +1,17 @@
 package logger
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 	"unsafe"
 )
 
+// Color is an ANSI color code, or a combination of them.
+type Color = string
+
 // ANSI color codes
 const (
 	Italics   string = "\x1b[3m"
@@ -82,6 +88,74 @@ func DeColorize(str string) string {
 	return remAnsiRex.ReplaceAllString(str, "")
 }
 
+// Sanitize escapes all control characters in a string, such as ANSI escape sequences.
+//
+// Newlines, "\r\n" line endings and tabs are kept, other control characters are
+// written as their escaped form (e.g. "\x1b"), so they cannot manipulate the terminal.
+func Sanitize(str string) string {
+	var b = &strings.Builder{}
+	b.Grow(len(str))
+	for i, r := range str {
+		switch {
+		case r == '\n', r == '\t':
+			b.WriteRune(r)
+		case r == '\r' && i+1 < len(str) && str[i+1] == '\n':
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(b, "\\x%02x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// A regular expression of which the matches are colorized.
+type highlight struct {
+	re    *regexp.Regexp
+	color Color
+}
+
+// Colorize all matches of the highlights in the string.
+//
+// When matches of highlights overlap, the match of the highlight added first wins.
+func applyHighlights(str string, highlights []highlight) string {
+	type match struct {
+		start, end int
+		color      Color
+	}
+	var matches []match
+	for _, h := range highlights {
+	loop:
+		for _, loc := range h.re.FindAllStringIndex(str, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			for _, m := range matches {
+				if loc[0] < m.end && m.start < loc[1] {
+					continue loop
+				}
+			}
+			matches = append(matches, match{loc[0], loc[1], h.color})
+		}
+	}
+	if len(matches) == 0 {
+		return str
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+	var b = &strings.Builder{}
+	var last int
+	for _, m := range matches {
+		b.WriteString(str[last:m.start])
+		b.WriteString(Colorize(str[m.start:m.end], m.color))
+		last = m.end
+	}
+	b.WriteString(str[last:])
+	return b.String()
+}
+
 // Helper function to write a string to a string builder, optionally colorizing it.
 func writeIfColorized(b *strings.Builder, colorized bool, text string, color ...string) {
 	if colorized {
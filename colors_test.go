@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	var tests = map[string]string{
+		"clear \x1b[2J screen": `clear \x1b[2J screen`,
+		"bell\a":               `bell\x07`,
+		"keeps\ttabs\n":        "keeps\ttabs\n",
+		"crlf\r\n":             "crlf\r\n",
+		"lone\rreturn":         `lone\x0dreturn`,
+	}
+	for in, want := range tests {
+		if got := Sanitize(in); got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSanitizeInput(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Colorized = true
+	l.SanitizeInput = true
+	l.Info("user input \x1b[2J")
+
+	var out = buf.String()
+	if strings.Contains(out, "\x1b[2J") {
+		t.Errorf("clear screen sequence was written: %q", out)
+	}
+	if !strings.Contains(out, `user input \x1b[2J`) {
+		t.Errorf("message is missing its escaped sequence: %q", out)
+	}
+	if !strings.Contains(out, ColorLevelInfo) {
+		t.Errorf("the colors of the logger were removed: %q", out)
+	}
+}
+
+func TestApplyHighlights(t *testing.T) {
+	var highlights = []highlight{
+		{regexp.MustCompile(`\d+\.\d+\.\d+\.\d+`), Red},
+		{regexp.MustCompile(`\d+`), Blue},
+	}
+	var got = applyHighlights("request from 10.0.0.1 failed with 503", highlights)
+	var want = "request from " + Colorize("10.0.0.1", Red) + " failed with " + Colorize("503", Blue)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if DeColorize(got) != "request from 10.0.0.1 failed with 503" {
+		t.Errorf("DeColorize does not strip the highlights: %q", DeColorize(got))
+	}
+}
+
+func TestAddHighlight(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.AddHighlight(regexp.MustCompile(`E\d{3}`), Red)
+	l.Info("failed with E042")
+	if strings.Contains(buf.String(), Red) {
+		t.Errorf("uncolored output is highlighted: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.Info("failed with E042")
+	if !strings.Contains(buf.String(), Colorize("E042", Red)) {
+		t.Errorf("match is not highlighted: %q", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// The import path of this package, frames of this package are skipped when looking up the component.
+var ownPackage = reflect.TypeOf(Logger{}).PkgPath()
+
+// Cache of the function name per program counter.
+//
+// Frames of this package are stored as an empty string.
+var callerCache sync.Map
+
+// callerFunc returns the fully qualified name of the function which called into the logger,
+// such as "github.com/user/pkg.(*Type).Method".
+//
+// Frames of this package are skipped, so wrappers such as the package-level functions are accounted for.
+func callerFunc() string {
+	var pcs [32]uintptr
+	var n = runtime.Callers(2, pcs[:])
+	for _, pc := range pcs[:n] {
+		if name, ok := callerCache.Load(pc); ok {
+			if name != "" {
+				return name.(string)
+			}
+			continue
+		}
+		var name string
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && packagePath(fn.Name()) != ownPackage {
+			name = fn.Name()
+		}
+		callerCache.Store(pc, name)
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// callerComponent returns the name of the package which called into the logger.
+func callerComponent() string {
+	var name = callerFunc()
+	if name == "" {
+		return ""
+	}
+	var pkg = packagePath(name)
+	return pkg[strings.LastIndexByte(pkg, '/')+1:]
+}
+
+// packagePath returns the import path of a fully qualified function name.
+//
+// For example "github.com/user/pkg.(*Type).Method" returns "github.com/user/pkg".
+func packagePath(funcName string) string {
+	var slash = strings.LastIndexByte(funcName, '/')
+	var dot = strings.IndexByte(funcName[slash+1:], '.')
+	if dot < 0 {
+		return funcName
+	}
+	return funcName[:slash+1+dot]
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPackagePath(t *testing.T) {
+	var tests = map[string]string{
+		"github.com/user/pkg.(*Type).Method": "github.com/user/pkg",
+		"github.com/user/pkg.Func.func1":     "github.com/user/pkg",
+		"main.main":                          "main",
+		"net/http.HandlerFunc.ServeHTTP":     "net/http",
+	}
+	for name, want := range tests {
+		if got := packagePath(name); got != want {
+			t.Errorf("packagePath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAutoComponent(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.AutoComponent = true
+
+	// Frames of this package are skipped, so the line of the test itself is tagged with the package which called it,
+	// and the line logged from the sort callback with the sort package.
+	l.Info("from testing")
+	var logged bool
+	sort.Slice([]int{2, 1}, func(i, j int) bool {
+		if !logged {
+			logged = true
+			l.Info("from sort")
+		}
+		return i < j
+	})
+
+	var lines = strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "component=testing") {
+		t.Errorf("unexpected component of %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "component=sort") {
+		t.Errorf("unexpected component of %q", lines[1])
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"io"
+)
+
+// Config is a snapshot of the settings of a logger.
+//
+// It is taken with Logger.Snapshot and put back with Logger.Restore.
+type Config struct {
+	logger Logger
+}
+
+// Snapshot returns the current settings of the logger.
+func (l *Logger) Snapshot() Config {
+	return Config{logger: *l}
+}
+
+// Restore sets the settings of the logger back to the snapshot.
+func (l *Logger) Restore(c Config) {
+	*l = c.logger
+}
+
+// GlobalConfig holds the package-level settings which apply to all loggers.
+//
+// It is taken with SnapshotGlobals and put back with RestoreGlobals,
+// for example to undo changes to the settings in tests.
+type GlobalConfig struct {
+	MaxMsgWidth        int
+	StacktracePathSize int
+	FallbackWriter     io.Writer
+	TestLevelEnabled   func() bool
+	Exit               func(code int)
+	IsTerminal         func(w io.Writer) bool
+
+	ColorLevelTest    Color
+	ColorLevelDebug   Color
+	ColorLevelInfo    Color
+	ColorLevelWarning Color
+	ColorLevelError   Color
+	ColorNoLevel      Color
+}
+
+// SnapshotGlobals returns the current package-level settings.
+func SnapshotGlobals() GlobalConfig {
+	return GlobalConfig{
+		MaxMsgWidth:        loggerMaxMsgWidth,
+		StacktracePathSize: stacktracePathSize,
+		FallbackWriter:     FallbackWriter,
+		TestLevelEnabled:   TestLevelEnabled,
+		Exit:               Exit,
+		IsTerminal:         IsTerminal,
+
+		ColorLevelTest:    ColorLevelTest,
+		ColorLevelDebug:   ColorLevelDebug,
+		ColorLevelInfo:    ColorLevelInfo,
+		ColorLevelWarning: ColorLevelWarning,
+		ColorLevelError:   ColorLevelError,
+		ColorNoLevel:      ColorNoLevel,
+	}
+}
+
+// RestoreGlobals sets the package-level settings back to the snapshot.
+func RestoreGlobals(c GlobalConfig) {
+	loggerMaxMsgWidth = c.MaxMsgWidth
+	stacktracePathSize = c.StacktracePathSize
+	FallbackWriter = c.FallbackWriter
+	TestLevelEnabled = c.TestLevelEnabled
+	Exit = c.Exit
+	IsTerminal = c.IsTerminal
+
+	ColorLevelTest = c.ColorLevelTest
+	ColorLevelDebug = c.ColorLevelDebug
+	ColorLevelInfo = c.ColorLevelInfo
+	ColorLevelWarning = c.ColorLevelWarning
+	ColorLevelError = c.ColorLevelError
+	ColorNoLevel = c.ColorNoLevel
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestLoggerSnapshotRestore(t *testing.T) {
+	var l, buf = newTestLogger(t, INFO, "app ")
+	var config = l.Snapshot()
+
+	l.Loglevel = DEBUG
+	l.Colorized = true
+	l.LevelOffset = 2
+	l.Uptime = true
+	l.File = &bytes.Buffer{}
+	l.AddHighlight(regexp.MustCompile("x"), Red)
+	l.Restore(config)
+
+	if l.Loglevel != INFO || l.Colorized || l.LevelOffset != 0 || l.Uptime || l.File != buf || len(l.highlights) != 0 {
+		t.Errorf("settings were not restored: %+v", l)
+	}
+	l.Info("restored")
+	if want := "2024-01-02 03:04:05 [app INFO] restored\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGlobalsSnapshotRestore(t *testing.T) {
+	restoreGlobals(t)
+	var config = SnapshotGlobals()
+
+	loggerMaxMsgWidth = 10
+	ColorLevelInfo = Red
+	RestoreGlobals(config)
+
+	var got = SnapshotGlobals()
+	if got.MaxMsgWidth != config.MaxMsgWidth || got.ColorLevelInfo != config.ColorLevelInfo {
+		t.Errorf("globals were not restored: got %+v, want %+v", got, config)
+	}
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// The process-wide default logger, used by the package-level logging functions.
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault sets the logger used by the package-level logging functions.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default returns the logger used by the package-level logging functions.
+//
+// If no default logger was set, an INFO logger writing to stderr is created.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	defaultLogger.CompareAndSwap(nil, NewLogger(INFO, os.Stderr))
+	return defaultLogger.Load()
+}
+
+// Write a critical error with the default logger, loglevel critical
+func Critical(err error) {
+	Default().logCritical(err, 1)
+}
+
+// Write a critical message with the default logger, loglevel critical
+func Criticalf(format string, args ...any) {
+	Default().logError(CRITICAL, fmt.Errorf(format, args...), 1)
+}
+
+// Write an error message with the default logger, loglevel error
+func Error(args ...any) {
+	Default().logErrorArgs(args, 1)
+}
+
+// Write an error message with the default logger, loglevel error
+func Errorf(format string, args ...any) {
+	Default().logError(ERROR, fmt.Errorf(format, args...), 1)
+}
+
+// Write a warning message with the default logger, loglevel warning
+func Warning(args ...any) {
+	Default().Warning(args...)
+}
+
+// Write a warning message with the default logger, loglevel warning
+func Warningf(format string, args ...any) {
+	Default().Warningf(format, args...)
+}
+
+// Write an info message with the default logger, loglevel info
+func Info(args ...any) {
+	Default().Info(args...)
+}
+
+// Write an info message with the default logger, loglevel info
+func Infof(format string, args ...any) {
+	Default().Infof(format, args...)
+}
+
+// Write a debug message with the default logger, loglevel debug
+func Debug(args ...any) {
+	Default().Debug(args...)
+}
+
+// Write a debug message with the default logger, loglevel debug
+func Debugf(format string, args ...any) {
+	Default().Debugf(format, args...)
+}
+
+// Write a test message with the default logger, loglevel test
+func Test(args ...any) {
+	Default().Test(args...)
+}
+
+// Write a test message with the default logger, loglevel test
+func Testf(format string, args ...any) {
+	Default().Testf(format, args...)
+}
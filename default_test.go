@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setTestDefault sets the default logger, and restores the previous default logger when the test ends.
+func setTestDefault(t *testing.T, l *Logger) {
+	var previous = defaultLogger.Load()
+	t.Cleanup(func() { defaultLogger.Store(previous) })
+	defaultLogger.Store(l)
+}
+
+func TestDefault(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	setTestDefault(t, l)
+	Info("x")
+	Errorf("failed %d times\n", 2)
+	if !strings.HasPrefix(buf.String(), "2024-01-02 03:04:05 [INFO] x\n2024-01-02 03:04:05 [ERROR] failed 2 times\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if Default() != l {
+		t.Error("Default does not return the logger which was set")
+	}
+
+	// The stacktrace must start at the caller of the package-level function, not in this package.
+	var writes = map[string]func(){
+		"Critical":  func() { Critical(errors.New("critical")) },
+		"Criticalf": func() { Criticalf("critical %d", 1) },
+		"Error":     func() { Error("error") },
+		"Errorf":    func() { Errorf("error %d", 1) },
+	}
+	for name, write := range writes {
+		buf.Reset()
+		write()
+		if !hasTestTrace(buf.String(), "default_test.go") {
+			t.Errorf("%s: stacktrace does not start at the caller:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestDefaultLazy(t *testing.T) {
+	setTestDefault(t, nil)
+	var l = Default()
+	if l == nil || l.Loglevel != INFO || l.File != os.Stderr {
+		t.Fatalf("unexpected lazy default logger %+v", l)
+	}
+	if Default() != l {
+		t.Error("the lazy default logger is created more than once")
+	}
+}
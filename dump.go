@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The maximum depth of a dump, if the DumpMaxDepth of the logger is not set.
+const defaultDumpMaxDepth = 8
+
+// Dump writes the value under the label at the loglevel, as an indented tree of its fields, elements and map entries.
+//
+// Pointers are followed, a pointer to a value which is already being written, such as in a cyclic graph, is written as <cycle>.
+// Structs, slices, arrays and maps nested deeper than the DumpMaxDepth are written as "...".
+func (l *Logger) Dump(level Loglevel, label string, value any) {
+	if !l.enabled(level) {
+		return
+	}
+	var maxDepth = l.DumpMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultDumpMaxDepth
+	}
+	l.logLine(level, label+": "+dump(value, maxDepth))
+}
+
+// dump returns the value as an indented tree, nested at most maxDepth levels deep.
+func dump(value any, maxDepth int) string {
+	var d = &dumper{
+		b:        &strings.Builder{},
+		maxDepth: maxDepth,
+		visiting: make(map[uintptr]bool),
+	}
+	d.value(reflect.ValueOf(value), 0)
+	return d.b.String()
+}
+
+// dumper writes a value as an indented tree, see Logger.Dump.
+type dumper struct {
+	b        *strings.Builder
+	maxDepth int
+
+	// The addresses of the pointers and maps which are being written, to detect cycles.
+	visiting map[uintptr]bool
+}
+
+func (d *dumper) value(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		d.b.WriteString("nil")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		if v.Kind() == reflect.Interface {
+			d.value(v.Elem(), depth)
+			return
+		}
+		if !d.enter(v.Pointer()) {
+			return
+		}
+		defer delete(d.visiting, v.Pointer())
+		d.b.WriteString("&")
+		d.value(v.Elem(), depth)
+	case reflect.Struct:
+		d.nested(v.Type().String(), depth, v.NumField(), func(i int) {
+			d.b.WriteString(v.Type().Field(i).Name)
+			d.b.WriteString(": ")
+			d.value(v.Field(i), depth+1)
+		})
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		d.nested(v.Type().String(), depth, v.Len(), func(i int) {
+			d.value(v.Index(i), depth+1)
+		})
+	case reflect.Map:
+		if v.IsNil() {
+			d.b.WriteString("nil")
+			return
+		}
+		if !d.enter(v.Pointer()) {
+			return
+		}
+		defer delete(d.visiting, v.Pointer())
+		// The keys are sorted by their text, so the output does not change between calls.
+		var keys = v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		d.nested(v.Type().String(), depth, len(keys), func(i int) {
+			d.value(keys[i], depth+1)
+			d.b.WriteString(": ")
+			d.value(v.MapIndex(keys[i]), depth+1)
+		})
+	case reflect.String:
+		d.b.WriteString(strconv.Quote(v.String()))
+	default:
+		// Unexported fields can not be turned into an interface, but fmt prints the value they hold.
+		fmt.Fprint(d.b, v)
+	}
+}
+
+// enter marks the address as being written, and writes <cycle> if it already was.
+func (d *dumper) enter(address uintptr) bool {
+	if d.visiting[address] {
+		d.b.WriteString("<cycle>")
+		return false
+	}
+	d.visiting[address] = true
+	return true
+}
+
+// nested writes the n items of a struct, slice, array or map on lines of their own,
+// indented one level deeper than the value, or "..." if the value is nested too deep.
+func (d *dumper) nested(typ string, depth, n int, item func(i int)) {
+	d.b.WriteString(typ)
+	if depth >= d.maxDepth {
+		d.b.WriteString("{...}")
+		return
+	}
+	d.b.WriteString("{")
+	for i := 0; i < n; i++ {
+		d.b.WriteString("\n")
+		d.b.WriteString(strings.Repeat("  ", depth+1))
+		item(i)
+	}
+	if n > 0 {
+		d.b.WriteString("\n")
+		d.b.WriteString(strings.Repeat("  ", depth))
+	}
+	d.b.WriteString("}")
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+type dumpNode struct {
+	Name  string
+	Tags  map[string]int
+	Next  *dumpNode
+	items []int
+}
+
+func TestDump(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Dump(INFO, "node", &dumpNode{Name: "a", Tags: map[string]int{"b": 2, "a": 1}, items: []int{1, 2}})
+
+	var want = `2024-01-02 03:04:05 [INFO] node: &logger.dumpNode{
+  Name: "a"
+  Tags: map[string]int{
+    "a": 1
+    "b": 2
+  }
+  Next: nil
+  items: []int{
+    1
+    2
+  }
+}
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDumpCycle(t *testing.T) {
+	var a = &dumpNode{Name: "a"}
+	a.Next = &dumpNode{Name: "b", Next: a}
+	var out = dump(a, defaultDumpMaxDepth)
+	if strings.Count(out, "<cycle>") != 1 || !strings.Contains(out, `Name: "b"`) {
+		t.Errorf("cycle is not written as <cycle>:\n%s", out)
+	}
+
+	// A value which is referred to twice, but not in a cycle, is written both times.
+	var shared = &dumpNode{Name: "shared"}
+	if out := dump([]*dumpNode{shared, shared}, defaultDumpMaxDepth); strings.Contains(out, "<cycle>") || strings.Count(out, "shared") != 2 {
+		t.Errorf("shared value is written as a cycle:\n%s", out)
+	}
+}
+
+func TestDumpMaxDepth(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.DumpMaxDepth = 2
+	var deep = &dumpNode{Name: "1", Next: &dumpNode{Name: "2", Next: &dumpNode{Name: "3"}}}
+	l.Dump(INFO, "deep", deep)
+	if !strings.Contains(buf.String(), `Name: "2"`) || strings.Contains(buf.String(), `Name: "3"`) {
+		t.Errorf("not nested 2 levels deep:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Next: &logger.dumpNode{...}") {
+		t.Errorf("deeper values are not written as ...:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The version of the Elastic Common Schema the ECSFormatter writes.
+const ecsVersion = "8.11.0"
+
+// ECSFormatter formats log entries as JSON in the Elastic Common Schema, a JSON object per line.
+//
+// The time, level and message are written as "@timestamp", "log.level" and "message",
+// the stacktrace as a string in "error.stack_trace".
+// Structured fields are written as top-level fields, dotted keys are nested.
+type ECSFormatter struct {
+	// LineTerminator is written after each object, defaults to "\n".
+	LineTerminator string
+}
+
+// Format formats the log entry as ECS JSON.
+func (f *ECSFormatter) Format(entry *LogEntry) []byte {
+	var doc = map[string]any{}
+	for _, kv := range entry.Fields {
+		if omitted(kv.Value, RenderJSONOnly) {
+			continue
+		}
+		setNested(doc, kv.Key, kv.Value)
+	}
+	setNested(doc, "@timestamp", entry.Time.UTC().Format(time.RFC3339Nano))
+	setNested(doc, "log.level", strings.ToLower(entry.Level.String()))
+	setNested(doc, "message", entry.Message)
+	setNested(doc, "ecs.version", ecsVersion)
+	if len(entry.Stacktrace) > 0 {
+		var b = &strings.Builder{}
+		for i := len(entry.Stacktrace) - 1; i >= 0; i-- {
+			var caller = entry.Stacktrace[i]
+			fmt.Fprintf(b, "%s()\n\t%s:%d\n", caller.FunctionName, caller.File, caller.Line)
+		}
+		setNested(doc, "error.stack_trace", b.String())
+	}
+
+	var b, err = json.Marshal(doc)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return append(b, lineTerminator(f.LineTerminator)...)
+}
+
+// ValidateOutput checks if the output is a single line of valid JSON.
+func (f *ECSFormatter) ValidateOutput(output []byte) error {
+	return (&JSONFormatter{LineTerminator: f.LineTerminator}).ValidateOutput(output)
+}
+
+// Set the value in the document, creating nested objects for dotted keys.
+//
+// If a key is already used by a non-object value, the object replaces it.
+func setNested(doc map[string]any, key string, value any) {
+	var parts = strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		var child, ok = doc[part].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			doc[part] = child
+		}
+		doc = child
+	}
+	doc[parts[len(parts)-1]] = value
+}
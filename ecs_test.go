@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestECSFormatter(t *testing.T) {
+	var entry = &LogEntry{
+		Time:       testTime,
+		Level:      ERROR,
+		Message:    "request failed",
+		Stacktrace: testFrames(2),
+		Fields:     NewFields("http.response.status_code", 500, "service", "api"),
+	}
+	var out = (&ECSFormatter{}).Format(entry)
+	if err := (&ECSFormatter{}).ValidateOutput(out); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Timestamp string `json:"@timestamp"`
+		Message   string `json:"message"`
+		Service   string `json:"service"`
+		Log       struct {
+			Level string `json:"level"`
+		} `json:"log"`
+		Error struct {
+			StackTrace string `json:"stack_trace"`
+		} `json:"error"`
+		HTTP struct {
+			Response struct {
+				StatusCode int `json:"status_code"`
+			} `json:"response"`
+		} `json:"http"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Timestamp != "2024-01-02T03:04:05Z" || doc.Log.Level != "error" || doc.Message != "request failed" {
+		t.Errorf("unexpected ECS fields: %s", out)
+	}
+	if doc.Service != "api" || doc.HTTP.Response.StatusCode != 500 {
+		t.Errorf("structured fields are not mapped: %s", out)
+	}
+	// The innermost frame comes first.
+	if !strings.HasPrefix(doc.Error.StackTrace, "app.frame01()\n\t/src/app/frame01.go:2\n") {
+		t.Errorf("unexpected stacktrace %q", doc.Error.StackTrace)
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// NewForEnvironment creates a logger with the preset of the environment:
+//
+//   - "dev": colorized text at loglevel debug, to stderr.
+//   - "staging": JSON at loglevel debug, to stderr.
+//   - "prod": JSON at loglevel info, to stderr.
+//
+// The JSON presets write to stderr, so the output is collected and rotated by the process manager, such as journald or docker.
+// An error is returned for other environments.
+func NewForEnvironment(env string) (*Logger, error) {
+	switch env {
+	case "dev":
+		return NewLogger(DEBUG, os.Stderr), nil
+	case "staging":
+		return NewJSONLogger(DEBUG, os.Stderr), nil
+	case "prod":
+		return NewJSONLogger(INFO, os.Stderr), nil
+	}
+	return nil, fmt.Errorf("logger: unknown environment %q, valid environments are dev, staging, prod", env)
+}
@@ -0,0 +1,25 @@
+package logger
+
+import "testing"
+
+func TestNewForEnvironment(t *testing.T) {
+	var dev, err = NewForEnvironment("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dev.Colorized || dev.Formatter != nil || dev.Loglevel != DEBUG {
+		t.Errorf("dev preset is not colorized text at DEBUG: %+v", dev)
+	}
+
+	prod, err := NewForEnvironment("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := prod.Formatter.(*JSONFormatter); !ok || prod.Loglevel != INFO {
+		t.Errorf("prod preset is not JSON at INFO: %+v", prod)
+	}
+
+	if _, err := NewForEnvironment("qa"); err == nil {
+		t.Error("unknown environment did not return an error")
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// expiryTracker remembers the day an expiry warning was last written per key.
+type expiryTracker struct {
+	mutex  *sync.Mutex
+	warned map[string]string
+}
+
+func newExpiryTracker() *expiryTracker {
+	return &expiryTracker{
+		mutex:  &sync.Mutex{},
+		warned: make(map[string]string),
+	}
+}
+
+// track reports whether a warning for the key should be written on the day of now.
+func (e *expiryTracker) track(now time.Time, key string) bool {
+	var day = now.Format("2006-01-02")
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.warned[key] == day {
+		return false
+	}
+	e.warned[key] = day
+	return true
+}
+
+// WarnExpiring writes a warning when the credential of the key expires within the given duration,
+// or has already expired.
+//
+// The warning is written at most once per key per day, so it can be called on every use of the credential.
+func (l *Logger) WarnExpiring(key string, expiresAt time.Time, within time.Duration) {
+	var now = l.now()
+	var left = expiresAt.Sub(now)
+	if left > within || !l.enabled(WARNING) {
+		return
+	}
+	if !l.shared().expiry.track(now, key) {
+		return
+	}
+	if left <= 0 {
+		l.Warningf("%s expired %s ago, at %s\n", key, (-left).Round(time.Second), expiresAt.Format(timeFormat))
+		return
+	}
+	l.Warningf("%s expires in %s, at %s\n", key, left.Round(time.Second), expiresAt.Format(timeFormat))
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWarnExpiring(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var now = testTime
+	l.Clock = func() time.Time { return now }
+	var tomorrow = testTime.Add(24 * time.Hour)
+
+	for i := 0; i < 5; i++ {
+		l.WarnExpiring("api-key", tomorrow, 7*24*time.Hour)
+		now = now.Add(time.Hour)
+	}
+	if got := strings.Count(buf.String(), "[WARNING] api-key expires in 24h0m0s"); got != 1 {
+		t.Errorf("warned %d times on the same day, want once:\n%s", got, buf.String())
+	}
+
+	l.WarnExpiring("db-password", testTime.Add(30*24*time.Hour), 7*24*time.Hour)
+	if strings.Contains(buf.String(), "db-password") {
+		t.Errorf("warned about a credential outside of the window:\n%s", buf.String())
+	}
+
+	// The next day, the credential has expired and is warned about again.
+	buf.Reset()
+	now = testTime.Add(48 * time.Hour)
+	l.WarnExpiring("api-key", tomorrow, 7*24*time.Hour)
+	if !strings.Contains(buf.String(), "[WARNING] api-key expired 24h0m0s ago") {
+		t.Errorf("no warning on the next day:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,237 @@
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A KeyValue is a single structured field of a log line.
+type KeyValue struct {
+	Key   string
+	Value any
+}
+
+// Fields is an ordered set of structured fields.
+//
+// The fields are always rendered in the order they were added,
+// adding a key which already exists replaces its value, but keeps its position.
+//
+// Fields are never modified in place, the methods return a new set of fields.
+type Fields []KeyValue
+
+// NewFields creates a new set of fields from alternating keys and values.
+//
+// Keys are formatted with fmt.Sprint, a trailing key without a value gets a nil value.
+func NewFields(keysAndValues ...any) Fields {
+	var f = make(Fields, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value any
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		f = f.With(fmt.Sprint(keysAndValues[i]), value)
+	}
+	return f
+}
+
+// With returns the fields with the key set to the value.
+func (f Fields) With(key string, value any) Fields {
+	var fields = make(Fields, len(f), len(f)+1)
+	copy(fields, f)
+	for i, kv := range fields {
+		if kv.Key == key {
+			fields[i].Value = value
+			return fields
+		}
+	}
+	return append(fields, KeyValue{Key: key, Value: value})
+}
+
+// Merge returns the fields with all of the other fields set.
+func (f Fields) Merge(other Fields) Fields {
+	if len(other) == 0 {
+		return f
+	}
+	var fields = f
+	for _, kv := range other {
+		fields = fields.With(kv.Key, kv.Value)
+	}
+	return fields
+}
+
+// Get returns the value of the key, and whether it exists.
+func (f Fields) Get(key string) (any, bool) {
+	for _, kv := range f {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the fields as space separated key=value pairs.
+//
+// Fields which are only rendered in JSON are left out, see RenderHint.
+func (f Fields) String() string {
+	var b = &strings.Builder{}
+	for _, kv := range f {
+		if omitted(kv.Value, RenderTextOnly) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(kv.Key)
+		b.WriteString("=")
+		b.WriteString(formatValue(kv.Value))
+	}
+	return b.String()
+}
+
+// MarshalJSON marshals the fields to a JSON object, keeping the order of the fields.
+//
+// Values which cannot be marshalled are written as their string representation.
+// Fields which are only rendered in text are left out, see RenderHint.
+func (f Fields) MarshalJSON() ([]byte, error) {
+	var b = &bytes.Buffer{}
+	b.WriteString("{")
+	var first = true
+	for _, kv := range f {
+		if omitted(kv.Value, RenderJSONOnly) {
+			continue
+		}
+		if !first {
+			b.WriteString(",")
+		}
+		first = false
+		var key, err = json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(marshalValue(kv.Value))
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// Format the value of a field as text.
+//
+// Slices and arrays are written as [a, b, c], their elements and other values with fmt.Sprint.
+// Values which implement fmt.Stringer are always written with their String method.
+func formatValue(value any) string {
+	if _, ok := value.(fmt.Stringer); ok {
+		return fmt.Sprint(value)
+	}
+	var v = reflect.ValueOf(value)
+	if !isList(v) {
+		return fmt.Sprint(value)
+	}
+	var b = &strings.Builder{}
+	b.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprint(v.Index(i).Interface()))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// Marshal the value of a field to JSON.
+//
+// Errors and fmt.Stringers are written as their string, unless they marshal themselves.
+// Values which cannot be marshalled are written as their string representation,
+// slices and arrays as an array, where both are done per element unless the list marshals itself.
+func marshalValue(value any) []byte {
+	if data, ok := marshalString(value); ok {
+		return data
+	}
+	var v = reflect.ValueOf(value)
+	if !isList(v) || marshalsItself(value) || (v.Kind() == reflect.Slice && v.IsNil()) {
+		var data, err = json.Marshal(value)
+		if err != nil {
+			data, _ = json.Marshal(fmt.Sprint(value))
+		}
+		return data
+	}
+	var b = &bytes.Buffer{}
+	b.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		var element = v.Index(i).Interface()
+		var data, ok = marshalString(element)
+		if !ok {
+			var err error
+			if data, err = json.Marshal(element); err != nil {
+				data, _ = json.Marshal(fmt.Sprint(element))
+			}
+		}
+		b.Write(data)
+	}
+	b.WriteString("]")
+	return b.Bytes()
+}
+
+// marshalString marshals an error or fmt.Stringer as its string,
+// which would otherwise marshal to the exported fields of its type, such as {}.
+// Values which marshal themselves are not handled.
+func marshalString(value any) ([]byte, bool) {
+	if value == nil || marshalsItself(value) {
+		return nil, false
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, false
+	}
+	var s string
+	switch v := value.(type) {
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return nil, false
+	}
+	var data, _ = json.Marshal(s)
+	return data, true
+}
+
+// Reports whether the value implements json.Marshaler or encoding.TextMarshaler.
+func marshalsItself(value any) bool {
+	switch value.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// Reports whether the value is a slice or array, other than a byte slice.
+func isList(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// Append the fields to the message as key=value pairs.
+//
+// The fields are written before the line ending of the message, if there is one.
+func appendFields(msg string, fields Fields) string {
+	var text = fields.String()
+	if text == "" {
+		return msg
+	}
+	var trimmed = strings.TrimRight(msg, "\r\n")
+	return trimmed + " " + text + msg[len(trimmed):]
+}
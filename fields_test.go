@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// A fmt.Stringer without exported fields.
+type testStringer struct{ name string }
+
+func (s testStringer) String() string { return "stringer " + s.name }
+
+// An error type, of which a nil pointer must not be called.
+type testError struct{}
+
+func (*testError) Error() string { return "test error" }
+
+func TestMarshalValue(t *testing.T) {
+	var tests = map[string]struct {
+		value any
+		want  string
+	}{
+		"error":           {errors.New("boom"), `"boom"`},
+		"stringer":        {testStringer{"a"}, `"stringer a"`},
+		"nil error":       {(*testError)(nil), `null`},
+		"errors":          {[]error{errors.New("a"), errors.New("b")}, `["a","b"]`},
+		"text marshaler":  {net.IPv4(127, 0, 0, 1), `"127.0.0.1"`},
+		"json marshaler":  {time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), `"2024-01-02T03:04:05Z"`},
+		"unmarshallable":  {make(chan int), ""},
+		"plain value":     {42, `42`},
+		"unmarshallables": {[]any{1, func() {}}, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got = string(marshalValue(tt.value))
+			if tt.want == "" {
+				if got == "" || got[0] != '"' && got[0] != '[' {
+					t.Errorf("got %s, want a string or array", got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		var f = NewFields("z", 1, "a", 2, "m", 3).With("b", 4).With("a", 5)
+		if got, want := f.String(), "z=1 a=5 m=3 b=4"; got != want {
+			t.Fatalf("text: got %q, want %q", got, want)
+		}
+		var data, err = json.Marshal(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(data), `{"z":1,"a":5,"m":3,"b":4}`; got != want {
+			t.Fatalf("json: got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestWithFieldsOrder(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.WithFields(NewFields("service", "api", "request_id", 7)).WithFields(NewFields("user", 1, "service", "web")).Info("request")
+	if want := "2024-01-02 03:04:05 [INFO] request service=web request_id=7 user=1\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestListFields(t *testing.T) {
+	var f = NewFields("tags", []string{"a", "b", "c"}, "ids", [2]int{1, 2}, "nested", [][]int{{1, 2}, {3}}, "empty", []string{})
+	if got, want := f.String(), "tags=[a, b, c] ids=[1, 2] nested=[[1 2], [3]] empty=[]"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+	var data, err = json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"tags":["a","b","c"],"ids":[1,2],"nested":[[1,2],[3]],"empty":[]}`; got != want {
+		t.Errorf("json: got %s, want %s", got, want)
+	}
+}
+
+// testList is a list which marshals itself as an object.
+type testList []string
+
+func (l testList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]int{"len": len(l)})
+}
+
+func TestMarshalValueListMarshaler(t *testing.T) {
+	if got := string(marshalValue(testList{"a", "b"})); got != `{"len":2}` {
+		t.Errorf("got %s, want the list to marshal itself", got)
+	}
+}
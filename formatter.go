@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Nigel2392/router/v3/middleware/tracer"
+)
+
+// A Formatter renders a log entry to the bytes which are written.
+type Formatter interface {
+	Format(entry *LogEntry) []byte
+}
+
+// A HeaderFormatter writes a header at the start of a new file or stream, before the first entry,
+// describing the format of the entries.
+type HeaderFormatter interface {
+	Header() []byte
+}
+
+// WriteHeader writes the header of the formatter to w, if the formatter is a HeaderFormatter.
+//
+// Call this when a new file or stream is started, before anything else is written to it.
+func WriteHeader(w io.Writer, f Formatter) error {
+	var h, ok = f.(HeaderFormatter)
+	if !ok {
+		return nil
+	}
+	var _, err = w.Write(h.Header())
+	return err
+}
+
+// The version of the format of the JSONFormatter, written in its header.
+const jsonFormatVersion = 2
+
+// A FormatValidator checks if the output of a formatter is well-formed.
+//
+// Formatters can implement this to be checked by ValidateFormatter.
+type FormatValidator interface {
+	ValidateOutput(output []byte) error
+}
+
+// TextFormatter formats log entries as human readable text.
+//
+// See LogEntry.AsString for the format.
+type TextFormatter struct {
+	// The prefix of the log entries.
+	Prefix string
+
+	// Colorize is a flag which determines whether the log entry is written colorized.
+	Colorize bool
+
+	// The settings for rendering the log entries, such as the LineTerminator.
+	RenderOptions
+}
+
+// Format formats the log entry as text.
+func (f *TextFormatter) Format(entry *LogEntry) []byte {
+	return []byte(entry.AsStringWith(f.Prefix, f.Colorize, f.RenderOptions))
+}
+
+// JSONFormatter formats log entries as a JSON object per line.
+//
+// The time is written in RFC3339 format, the level by its name,
+// and the stacktrace as an array of objects with the file, line and function of each frame.
+type JSONFormatter struct {
+	// LineTerminator is written after each object, defaults to "\n".
+	LineTerminator string
+}
+
+// The JSON object of a log entry written by the JSONFormatter.
+type jsonEntry struct {
+	Time       string      `json:"time"`
+	Level      string      `json:"level"`
+	Message    string      `json:"message"`
+	Stacktrace []jsonFrame `json:"stacktrace,omitempty"`
+	Fields     Fields      `json:"fields,omitempty"`
+}
+
+// A frame of the stacktrace written by the JSONFormatter.
+type jsonFrame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+// Format formats the log entry as JSON.
+func (f *JSONFormatter) Format(entry *LogEntry) []byte {
+	var e = jsonEntry{
+		Time:    entry.Time.Format(time.RFC3339Nano),
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	}
+	for _, caller := range entry.Stacktrace {
+		e.Stacktrace = append(e.Stacktrace, jsonFrame{File: caller.File, Line: caller.Line, Function: caller.FunctionName})
+	}
+	var b, err = json.Marshal(e)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return append(b, lineTerminator(f.LineTerminator)...)
+}
+
+// Header returns a "_meta" record, with the version and fields of the format and the identity of the process.
+func (f *JSONFormatter) Header() []byte {
+	var host, _ = os.Hostname()
+	var b, _ = json.Marshal(map[string]any{
+		"_meta": map[string]any{
+			"format":  "json",
+			"version": jsonFormatVersion,
+			"fields":  []string{"time", "level", "message", "stacktrace", "fields"},
+			"process": filepath.Base(os.Args[0]),
+			"pid":     os.Getpid(),
+			"host":    host,
+		},
+	})
+	return append(b, lineTerminator(f.LineTerminator)...)
+}
+
+// ValidateOutput checks if the output is a single line of valid JSON.
+func (f *JSONFormatter) ValidateOutput(output []byte) error {
+	var line = bytes.TrimSuffix(output, []byte(lineTerminator(f.LineTerminator)))
+	if err := validateSingleLine(line); err != nil {
+		return err
+	}
+	if !json.Valid(line) {
+		return fmt.Errorf("invalid JSON: %s", line)
+	}
+	return nil
+}
+
+// LogfmtFormatter formats log entries as a line of logfmt key=value pairs.
+type LogfmtFormatter struct {
+	// LineTerminator is written after each line, defaults to "\n".
+	LineTerminator string
+}
+
+// Format formats the log entry as logfmt.
+func (f *LogfmtFormatter) Format(entry *LogEntry) []byte {
+	var b = &bytes.Buffer{}
+	b.WriteString("time=")
+	b.WriteString(entry.Time.Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(entry.Level.String())
+	b.WriteString(" msg=")
+	b.WriteString(logfmtValue(entry.Message))
+	for _, kv := range entry.Fields {
+		if omitted(kv.Value, RenderTextOnly) {
+			continue
+		}
+		b.WriteString(" ")
+		b.WriteString(kv.Key)
+		b.WriteString("=")
+		b.WriteString(logfmtValue(formatValue(kv.Value)))
+	}
+	if len(entry.Stacktrace) > 0 {
+		var caller = entry.Stacktrace[len(entry.Stacktrace)-1]
+		b.WriteString(" caller=")
+		b.WriteString(logfmtValue(fmt.Sprintf("%s:%d", caller.File, caller.Line)))
+	}
+	b.WriteString(lineTerminator(f.LineTerminator))
+	return b.Bytes()
+}
+
+// ValidateOutput checks if the output is a single line of key=value pairs.
+func (f *LogfmtFormatter) ValidateOutput(output []byte) error {
+	var line = string(bytes.TrimSuffix(output, []byte(lineTerminator(f.LineTerminator))))
+	if err := validateSingleLine([]byte(line)); err != nil {
+		return err
+	}
+	for line != "" {
+		var eq = strings.IndexByte(line, '=')
+		if eq <= 0 || strings.ContainsAny(line[:eq], " \"") {
+			return fmt.Errorf("invalid logfmt key in: %s", line)
+		}
+		line = line[eq+1:]
+		if strings.HasPrefix(line, `"`) {
+			var value, err = strconv.QuotedPrefix(line)
+			if err != nil {
+				return fmt.Errorf("invalid logfmt value in: %s", line)
+			}
+			line = line[len(value):]
+		} else if i := strings.IndexByte(line, ' '); i >= 0 {
+			line = line[i:]
+		} else {
+			line = ""
+		}
+		if line != "" && !strings.HasPrefix(line, " ") {
+			return fmt.Errorf("missing separator in: %s", line)
+		}
+		line = strings.TrimPrefix(line, " ")
+	}
+	return nil
+}
+
+// Quote a logfmt value if it contains spaces, quotes or control characters.
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || unicode.IsControl(r) || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// Entries which are hard to format correctly, used to validate formatters.
+var validationSamples = []*LogEntry{
+	{
+		Level:   INFO,
+		Message: "a simple message",
+	},
+	{
+		Level:   WARNING,
+		Message: "a \"quoted\" message\nspanning multiple lines\r\nwith\ttabs = and equals",
+	},
+	{
+		Level:   ERROR,
+		Message: "a message with a stacktrace and \x1b[31mcontrol characters\x1b[0m",
+		Stacktrace: tracer.StackTrace{
+			{File: "/path/to/main.go", Line: 10, FunctionName: "main.main"},
+			{File: "/path/to/some file.go", Line: 20, FunctionName: "main.(*T).Method"},
+		},
+	},
+	{
+		Level:   DEBUG,
+		Message: "a message with fields",
+		Fields:  NewFields("key", "value", "quoted", `"value"`, "spaced", "some value", "number", 42),
+	},
+	{
+		Level: CRITICAL,
+	},
+}
+
+// ValidateFormatter renders a set of tricky sample entries with the formatter,
+// and checks if the output is well-formed.
+//
+// Every entry must render to a non-empty output which ends with a line ending, "\n" or "\r\n".
+// If the formatter implements FormatValidator, the output is also checked by it.
+func ValidateFormatter(f Formatter) error {
+	for _, sample := range validationSamples {
+		var entry = *sample
+		entry.Time = time.Now()
+		var output = f.Format(&entry)
+		if len(output) == 0 {
+			return fmt.Errorf("empty output for message %q", entry.Message)
+		}
+		if !bytes.HasSuffix(output, []byte("\n")) {
+			return fmt.Errorf("output for message %q does not end with a line ending", entry.Message)
+		}
+		if v, ok := f.(FormatValidator); ok {
+			if err := v.ValidateOutput(output); err != nil {
+				return fmt.Errorf("output for message %q: %w", entry.Message, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Check that the line contains no line endings or other control characters.
+func validateSingleLine(line []byte) error {
+	for _, r := range string(line) {
+		if r == '\n' || r == '\r' {
+			return errors.New("output spans multiple lines")
+		}
+		if unicode.IsControl(r) {
+			return fmt.Errorf("unescaped control character %q", r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// rawFormatter writes the message as is, so multi-line messages break its lines.
+type rawFormatter struct{ JSONFormatter }
+
+func (rawFormatter) Format(entry *LogEntry) []byte {
+	return []byte(`{"message":"` + entry.Message + `"}` + "\n")
+}
+
+func TestValidateFormatter(t *testing.T) {
+	restoreGlobals(t)
+	for name, f := range map[string]Formatter{
+		"json":   &JSONFormatter{},
+		"logfmt": &LogfmtFormatter{},
+	} {
+		if err := ValidateFormatter(f); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		var output = f.Format(&LogEntry{Time: testTime, Level: INFO, Message: "say \"hi\"\nand bye"})
+		if err := f.(FormatValidator).ValidateOutput(output); err != nil {
+			t.Errorf("%s: output %q: %v", name, output, err)
+		}
+	}
+	if err := ValidateFormatter(&rawFormatter{}); err == nil {
+		t.Error("a formatter which does not escape messages passed")
+	}
+}
+
+func TestAuditHeaderOnce(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var audit = &bytes.Buffer{}
+	l.AuditWriter = audit
+	l.Audit("login", nil)
+	l.WithFields(NewFields("user", 1)).Audit("logout", nil)
+
+	var lines = strings.Split(strings.TrimSuffix(audit.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], `{"_meta":`) || strings.Count(audit.String(), `"_meta"`) != 1 {
+		t.Errorf("want the header once at the top:\n%s", audit.String())
+	}
+	var header struct {
+		Meta struct {
+			Format  string   `json:"format"`
+			Version int      `json:"version"`
+			Fields  []string `json:"fields"`
+			Pid     int      `json:"pid"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Meta.Format != "json" || header.Meta.Version != jsonFormatVersion || len(header.Meta.Fields) == 0 || header.Meta.Pid != os.Getpid() {
+		t.Errorf("unexpected header %+v", header.Meta)
+	}
+}
+
+func TestWriteHeader(t *testing.T) {
+	// A new file, such as after a rotation, gets its own header.
+	for i := 0; i < 2; i++ {
+		var file = &bytes.Buffer{}
+		if err := WriteHeader(file, &JSONFormatter{}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(file.String(), `{"_meta":`) || strings.Count(file.String(), "\n") != 1 {
+			t.Errorf("file %d: unexpected header %q", i, file.String())
+		}
+	}
+
+	var file = &bytes.Buffer{}
+	WriteHeader(file, &LogfmtFormatter{})
+	if file.Len() != 0 {
+		t.Errorf("a formatter without a header wrote %q", file.String())
+	}
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// LogGroup collects related log lines, and writes them as one block when the group ends.
+//
+// This keeps the lines together, instead of interleaving them with the lines of other goroutines.
+type LogGroup struct {
+	// The logger the group is written to.
+	logger *Logger
+
+	// The name of the group, written in the header of the block.
+	name string
+
+	// The rendered lines of the group.
+	lines *strings.Builder
+
+	// The most severe loglevel of the lines, the block is written with this level.
+	level Loglevel
+
+	// The mutex used to lock the lines.
+	mutex *sync.Mutex
+}
+
+// Group starts a new group of log lines with the given name.
+//
+// The lines are written when LogGroup.End is called.
+func (l *Logger) Group(name string) *LogGroup {
+	return &LogGroup{
+		logger: l,
+		name:   name,
+		lines:  &strings.Builder{},
+		level:  INFO,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Write an error message to the group, loglevel error, including the stacktrace of the call.
+func (g *LogGroup) Error(args ...any) {
+	if benignArgs(args) {
+		g.log(ERROR, fmt.Sprint(args...))
+		return
+	}
+	g.logTrace(ERROR, errors.New(fmt.Sprint(args...)), 1)
+}
+
+// Write an error message to the group, loglevel error, including the stacktrace of the call.
+func (g *LogGroup) Errorf(format string, args ...any) {
+	g.logTrace(ERROR, fmt.Errorf(format, args...), 1)
+}
+
+// Write a warning message to the group, loglevel warning
+func (g *LogGroup) Warning(args ...any) {
+	g.log(WARNING, fmt.Sprint(args...))
+}
+
+// Write a warning message to the group, loglevel warning
+func (g *LogGroup) Warningf(format string, args ...any) {
+	g.log(WARNING, fmt.Sprintf(format, args...))
+}
+
+// Write an info message to the group, loglevel info
+func (g *LogGroup) Info(args ...any) {
+	g.log(INFO, fmt.Sprint(args...))
+}
+
+// Write an info message to the group, loglevel info
+func (g *LogGroup) Infof(format string, args ...any) {
+	g.log(INFO, fmt.Sprintf(format, args...))
+}
+
+// Write a debug message to the group, loglevel debug
+func (g *LogGroup) Debug(args ...any) {
+	g.log(DEBUG, fmt.Sprint(args...))
+}
+
+// Write a debug message to the group, loglevel debug
+func (g *LogGroup) Debugf(format string, args ...any) {
+	g.log(DEBUG, fmt.Sprintf(format, args...))
+}
+
+// End writes the lines of the group as a single block, under a header with the name of the group.
+//
+// Nothing is written if the group has no lines.
+func (g *LogGroup) End() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.lines.Len() == 0 {
+		return
+	}
+	var t = g.logger.now()
+	var header = g.logger.render(t, INFO, fmt.Sprintf("Group: %s\n", g.name))
+	g.logger.writeRaw(t, g.level, header+g.lines.String(), nil, false)
+	g.lines.Reset()
+	g.level = INFO
+}
+
+func (g *LogGroup) log(level Loglevel, msg string) {
+	if !g.logger.enabled(level) {
+		return
+	}
+	msg = lineOf(msg)
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.lines.WriteString(g.logger.render(g.logger.now(), level, msg))
+	if level.MoreSevereThan(g.level) {
+		g.level = level
+	}
+}
+
+// logTrace adds the error to the group, with the stacktrace of the call under the message.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logTrace.
+func (g *LogGroup) logTrace(level Loglevel, err error, skip int) {
+	if !g.logger.enabled(level) {
+		return
+	}
+	var entry = g.logger.traceEntry(level, err, skip+1)
+	entry.Fields = g.logger.lineFields()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.lines.WriteString(g.logger.renderEntry(entry))
+}
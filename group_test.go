@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGroupsAreContiguous(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var buf = &syncBuffer{}
+	l.File = buf
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			var g = l.Group("job " + name)
+			for i := 0; i < 50; i++ {
+				g.Infof("step %s%d\n", name, i)
+				l.Debug("between")
+			}
+			g.End()
+		}(name)
+	}
+	wg.Wait()
+
+	var lines = strings.Split(buf.buf.String(), "\n")
+	for _, name := range []string{"a", "b"} {
+		var start = -1
+		for i, line := range lines {
+			if strings.HasSuffix(line, "Group: job "+name) {
+				start = i
+			}
+		}
+		if start < 0 {
+			t.Fatalf("missing the header of group %s", name)
+		}
+		for i := 0; i < 50; i++ {
+			if want := fmt.Sprintf("step %s%d", name, i); !strings.HasSuffix(lines[start+1+i], want) {
+				t.Fatalf("line %d of group %s is %q, want %q", i, name, lines[start+1+i], want)
+			}
+		}
+	}
+}
+
+func TestGroupEmpty(t *testing.T) {
+	var l, buf = newTestLogger(t, INFO)
+	var g = l.Group("empty")
+	g.Debug("dropped")
+	g.End()
+	if buf.Len() != 0 {
+		t.Errorf("empty group wrote:\n%s", buf.String())
+	}
+}
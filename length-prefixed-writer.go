@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// LengthPrefixedWriter frames every write as a record, prefixed with its length as a 4-byte big-endian integer.
+//
+// The trailing line ending of each record, "\n" or "\r\n", is removed, the length prefix frames the record instead.
+// This makes records reliable to parse over a stream, such as a TCP connection.
+//
+// It is safe for concurrent use.
+type LengthPrefixedWriter struct {
+	// The writer the records are written to.
+	w io.Writer
+
+	// The mutex used to write records in one piece.
+	mutex *sync.Mutex
+}
+
+// NewLengthPrefixedWriter creates a new writer which writes length-prefixed records to w.
+func NewLengthPrefixedWriter(w io.Writer) *LengthPrefixedWriter {
+	return &LengthPrefixedWriter{
+		w:     w,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Write writes p to the underlying writer as a single record.
+func (l *LengthPrefixedWriter) Write(p []byte) (int, error) {
+	var payload = p
+	if bytes.HasSuffix(payload, []byte("\n")) {
+		payload = bytes.TrimSuffix(payload[:len(payload)-1], []byte("\r"))
+	}
+	var record, err = lengthPrefixed(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.w.Write(record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lengthPrefixed returns the payload as a record, prefixed with its length as a 4-byte big-endian integer.
+func lengthPrefixed(payload []byte) ([]byte, error) {
+	if uint64(len(payload)) > math.MaxUint32 {
+		return nil, fmt.Errorf("logger: record of %d bytes is too large to be length-prefixed", len(payload))
+	}
+	var record = make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	copy(record[4:], payload)
+	return record, nil
+}
+
+// readLengthPrefixed reads a record written by lengthPrefixed, and returns its payload.
+//
+// io.EOF is returned when there are no more records, io.ErrUnexpectedEOF when a record is cut off.
+func readLengthPrefixed(r io.Reader) ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	var payload = make([]byte, binary.BigEndian.Uint32(prefix[:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Unwrap returns the underlying writer.
+func (l *LengthPrefixedWriter) Unwrap() io.Writer {
+	return l.w
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestLengthPrefixedWriter(t *testing.T) {
+	restoreGlobals(t)
+	var buf = &bytes.Buffer{}
+	var w = NewLengthPrefixedWriter(buf)
+	w.Write([]byte("first record\n"))
+	w.Write([]byte("second\nrecord"))
+
+	// Decode the stream by hand, from the length prefixes.
+	var stream = buf.Bytes()
+	var records []string
+	for len(stream) >= 4 {
+		var n = binary.BigEndian.Uint32(stream)
+		records = append(records, string(stream[4:4+n]))
+		stream = stream[4+n:]
+	}
+	if len(stream) != 0 || len(records) != 2 || records[0] != "first record" || records[1] != "second\nrecord" {
+		t.Errorf("decoded %q with %d bytes left", records, len(stream))
+	}
+}
+
+func TestReadLengthPrefixed(t *testing.T) {
+	var record, _ = lengthPrefixed([]byte("payload"))
+	var r = bytes.NewReader(append(record, record[:6]...))
+	if payload, err := readLengthPrefixed(r); err != nil || string(payload) != "payload" {
+		t.Errorf("read %q, %v", payload, err)
+	}
+	if _, err := readLengthPrefixed(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("cut off record: got %v, want io.ErrUnexpectedEOF", err)
+	}
+	if _, err := readLengthPrefixed(r); err != io.EOF {
+		t.Errorf("end of stream: got %v, want io.EOF", err)
+	}
+}
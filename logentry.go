@@ -3,7 +3,9 @@ package logger
 import (
 	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,14 +17,73 @@ var (
 	stacktracePathSize = 40
 )
 
+// The time the process started, relative times are measured from this.
+var startTime = time.Now()
+
+// RenderOptions are the settings for rendering log entries as text, see LogEntry.AsStringWith.
+//
+// The zero value renders with the defaults. The options are embedded in Logger, BatchLogger and TextFormatter,
+// so loggers which write to different places can render differently.
+type RenderOptions struct {
+	// LineTerminator is written at the end of each line of a log entry, defaults to "\n".
+	//
+	// Set this to "\r\n" for tools which expect CRLF line endings.
+	LineTerminator string
+
+	// MaxRenderedFrames is the maximum amount of stacktrace frames rendered.
+	//
+	// The frames closest to the call are rendered, the outer frames are summarized in a note after them.
+	// The full stacktrace is kept on the LogEntry, a value <= 0 renders all frames.
+	MaxRenderedFrames int
+
+	// StacktraceFunctionNames renders the function name of each stacktrace frame
+	// as an extra column, between the file name and the path.
+	StacktraceFunctionNames bool
+
+	// QuoteMessage writes messages in double quotes, escaping quotes, newlines and other special characters.
+	//
+	// This makes each message an unambiguous token for parsers, the stacktrace is written outside of the quotes.
+	QuoteMessage bool
+
+	// MaxPrefixWidth is the maximum amount of columns the prefix of a line may take up.
+	//
+	// Longer prefixes are cut with CutEnd, keeping trailing spaces. A value <= 0 never cuts the prefix.
+	MaxPrefixWidth int
+
+	// SourceContext is the amount of source lines rendered before and after the line of each stacktrace frame.
+	//
+	// The source is read from disk, frames of which the file cannot be read are rendered without source.
+	// A value <= 0 renders no source, which is the default, as reading the source is slow.
+	SourceContext int
+
+	// RelativeTime renders the time elapsed since the process started instead of the timestamp.
+	//
+	// This is useful for short-lived programs, where the wall-clock time is noise.
+	RelativeTime bool
+}
+
+// lineTerminator returns the LineTerminator, or "\n" if it is not set.
+func (o RenderOptions) lineTerminator() string {
+	return lineTerminator(o.LineTerminator)
+}
+
+// lineTerminator returns the terminator, or "\n" if it is empty.
+func lineTerminator(terminator string) string {
+	if terminator == "" {
+		return "\n"
+	}
+	return terminator
+}
+
 // A entry to be logged.
 //
 // This may include a list of callers (Stacktrace)
 type LogEntry struct {
-	Time       time.Time         `json:"time"`       // The time the log entry was created.
-	Level      Loglevel          `json:"level"`      // The level of the log entry.
-	Message    string            `json:"message"`    // The message of the log entry.
-	Stacktrace tracer.StackTrace `json:"stacktrace"` // The tracer of the log entry.
+	Time       time.Time         `json:"time"`             // The time the log entry was created.
+	Level      Loglevel          `json:"level"`            // The level of the log entry.
+	Message    string            `json:"message"`          // The message of the log entry.
+	Stacktrace tracer.StackTrace `json:"stacktrace"`       // The tracer of the log entry.
+	Fields     Fields            `json:"fields,omitempty"` // The structured fields of the log entry.
 }
 
 // Intialize a new log entry.
@@ -34,13 +95,85 @@ type LogEntry struct {
 // stackTraceLen: The length of the stacktrace.
 //
 // skip: The number of frames to skip in the stacktrace.
+//
+// If the stacktrace cannot be captured, the entry has no stacktrace and a note is added to the message.
 func NewLogEntry(level Loglevel, message string, stackTraceLen, skip int) *LogEntry {
+	var trace, ok = captureTrace(false, errors.New(message), stackTraceLen, skip+1)
+	if !ok {
+		message += " " + stacktraceUnavailable
+	}
 	return &LogEntry{
 		Time:       time.Now(),
 		Level:      level,
 		Message:    message,
-		Stacktrace: tracer.Trace(errors.New(message), stackTraceLen, skip+1).Trace(),
+		Stacktrace: trace,
+	}
+}
+
+// Format the time of a log entry, as absolute or relative time.
+func (o RenderOptions) formatTime(t time.Time) string {
+	if o.RelativeTime {
+		return fmt.Sprintf("+%.3fs", t.Sub(startTime).Seconds())
 	}
+	return t.Format(timeFormat)
+}
+
+// Append the formatted time of a log entry to b, see formatTime.
+func (o RenderOptions) appendTime(b []byte, t time.Time) []byte {
+	if o.RelativeTime {
+		b = append(b, '+')
+		b = strconv.AppendFloat(b, t.Sub(startTime).Seconds(), 'f', 3, 64)
+		return append(b, 's')
+	}
+	return t.AppendFormat(b, timeFormat)
+}
+
+// BySeverity reports whether entry a is more severe than entry b.
+//
+// It can be used to flush the most severe entries of an accumulator first.
+func BySeverity(a, b *LogEntry) bool {
+	return a.Level.MoreSevereThan(b.Level)
+}
+
+// Estimates of the rendered size of parts of a log entry, used to grow the builder of AsString once.
+const (
+	estimateHeaderSize = 64  // The time, level and brackets of the header, and the stacktrace title.
+	estimateFrameSize  = 24  // The line number, function column and padding of a frame, excluding the paths.
+	estimateColorSize  = 40  // The color codes of a header or frame.
+	estimateSourceSize = 100 // A source line rendered for RenderOptions.SourceContext.
+)
+
+// estimateSize estimates the size of the string returned by AsString, so the builder only has to grow once.
+func (e *LogEntry) estimateSize(prefix, message string, colorized bool, opts RenderOptions) int {
+	var size = estimateHeaderSize + len(prefix) + len(message)
+	for _, kv := range e.Fields {
+		size += len(kv.Key) + 16
+	}
+	if colorized {
+		size += estimateColorSize
+	}
+	if e.Level.LessSevereThan(ERROR) || len(e.Stacktrace) == 0 {
+		return size
+	}
+	return size + e.estimateStacktraceSize(colorized, opts)
+}
+
+// estimateStacktraceSize estimates the size of the string returned by stacktraceString.
+func (e *LogEntry) estimateStacktraceSize(colorized bool, opts RenderOptions) int {
+	var frames = len(e.Stacktrace)
+	if opts.MaxRenderedFrames > 0 && frames > opts.MaxRenderedFrames {
+		frames = opts.MaxRenderedFrames
+	}
+	// The file name is usually shorter than the cut path.
+	var frameSize = estimateFrameSize + stacktracePathSize + stacktracePathSize/2
+	if colorized {
+		frameSize += estimateColorSize
+	}
+	if opts.SourceContext > 0 {
+		frameSize += (2*opts.SourceContext + 1) * estimateSourceSize
+	}
+	// Each frame, and the line under the stacktrace.
+	return (frames + 1) * frameSize
 }
 
 // Generate a string representation of the log entry.
@@ -48,10 +181,21 @@ func NewLogEntry(level Loglevel, message string, stackTraceLen, skip int) *LogEn
 // prefix: A prefix to add to the log entry.
 //
 // colorized: If the log entry should be colorized.
+//
+// The entry is rendered with the default RenderOptions, see AsStringWith.
 func (e *LogEntry) AsString(prefix string, colorized bool) string {
+	return e.AsStringWith(prefix, colorized, RenderOptions{})
+}
+
+// AsStringWith generates a string representation of the log entry like AsString, rendered with the options.
+func (e *LogEntry) AsStringWith(prefix string, colorized bool, opts RenderOptions) string {
+	var message = e.Message
+	if opts.QuoteMessage {
+		message = strconv.Quote(message)
+	}
 	var charAfterNewLineOrMultiLine bool
 	var multiLine bool
-	for _, c := range e.Message {
+	for _, c := range message {
 		if c == '\n' {
 			multiLine = true
 		}
@@ -61,16 +205,17 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 		}
 	}
 	var b = &strings.Builder{}
-	if charAfterNewLineOrMultiLine || len(e.Message) > loggerMaxMsgWidth {
+	b.Grow(e.estimateSize(prefix, message, colorized, opts))
+	if charAfterNewLineOrMultiLine || len(message) > loggerMaxMsgWidth {
 		b.WriteString("[ ")
 		if prefix != "" {
 			writeIfColorized(b, colorized, prefix, DimGrey)
 		}
 		writeIfColorized(b, colorized, e.Level.String(), getLogLevelColor(e.Level))
 		b.WriteString(" ] - ")
-		writeIfColorized(b, colorized, e.Time.Format("2006-01-02 15:04:05"), DimGrey)
+		writeIfColorized(b, colorized, opts.formatTime(e.Time), DimGrey)
 	} else {
-		writeIfColorized(b, colorized, e.Time.Format("2006-01-02 15:04:05"), DimGrey, Bold)
+		writeIfColorized(b, colorized, opts.formatTime(e.Time), DimGrey, Bold)
 		b.WriteString(" [ ")
 		if prefix != "" {
 			writeIfColorized(b, colorized, prefix, DimGrey)
@@ -78,25 +223,55 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 		writeIfColorized(b, colorized, e.Level.String(), getLogLevelColor(e.Level))
 		b.WriteString(" ] - ")
 	}
-	if e.Message != "" {
+	if message != "" {
 		if charAfterNewLineOrMultiLine {
 			b.WriteString("\n\n")
 		}
-		b.WriteString(e.Message)
+		b.WriteString(message)
+	}
+	if fields := e.Fields.String(); fields != "" {
+		b.WriteString(" ")
+		writeIfColorized(b, colorized, fields, DimGrey)
 	}
 
-	// Write the stacktrace of the message.
-	if e.Level > ERROR || e.Stacktrace == nil {
-		b.WriteString("\n")
-		return b.String()
+	b.WriteString("\n")
+	var line = terminateLines(b.String(), opts.LineTerminator)
+
+	// Only the stacktrace of errors is written.
+	if e.Level.LessSevereThan(ERROR) {
+		return line
+	}
+	return e.withStacktrace(line, colorized, opts)
+}
+
+// withStacktrace returns the rendered line of the entry, followed by its stacktrace if it has one.
+//
+// AsStringWith and the Logger render the line of the entry differently, but write the stacktrace under it the same way.
+func (e *LogEntry) withStacktrace(line string, colorized bool, opts RenderOptions) string {
+	if len(e.Stacktrace) == 0 {
+		return line
 	}
+	return line + terminateLines(e.stacktraceString(colorized, maxLineWidth(line), opts), opts.LineTerminator)
+}
 
-	b.WriteString("\n\n")
+// stacktraceString renders the stacktrace of the entry, as written under the message of an error.
+//
+// The ruler under the stacktrace is as wide as its widest line, and at least width columns.
+func (e *LogEntry) stacktraceString(colorized bool, width int, opts RenderOptions) string {
+	var b = &strings.Builder{}
+	b.Grow(e.estimateStacktraceSize(colorized, opts))
+	b.WriteString("\n")
 	writeIfColorized(b, colorized, "Stacktrace:\n", Red, Underline)
 
+	// The frames are ordered from the outermost to the innermost call, keep the frames closest to the call.
+	var stacktrace = e.Stacktrace
+	if opts.MaxRenderedFrames > 0 && len(stacktrace) > opts.MaxRenderedFrames {
+		stacktrace = stacktrace[len(stacktrace)-opts.MaxRenderedFrames:]
+	}
+
 	var maxLenStart int
-	var startSlice []string = make([]string, 0, len(e.Stacktrace))
-	for _, caller := range e.Stacktrace {
+	var startSlice []string = make([]string, 0, len(stacktrace))
+	for _, caller := range stacktrace {
 		var start = fmt.Sprintf("Error on line %d:", caller.Line)
 		startSlice = append(startSlice, start)
 		if len(start) > maxLenStart {
@@ -104,8 +279,8 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 		}
 	}
 	var maxMiddleLen int
-	var middleSlice []string = make([]string, 0, len(e.Stacktrace))
-	for _, caller := range e.Stacktrace {
+	var middleSlice []string = make([]string, 0, len(stacktrace))
+	for _, caller := range stacktrace {
 		if caller.FunctionName == "" {
 			middleSlice = append(middleSlice, "???")
 			continue
@@ -113,11 +288,27 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 		var _, file = filepath.Split(caller.File)
 		var middle = fmt.Sprintf("%s()", CutStart(file, stacktracePathSize, ".", false))
 		middleSlice = append(middleSlice, middle)
-		if len(middle) > maxMiddleLen {
-			maxMiddleLen = len(middle)
+		if width := VisibleWidth(middle); width > maxMiddleLen {
+			maxMiddleLen = width
 		}
 	}
-	for i, caller := range e.Stacktrace {
+	var maxFuncLen int
+	var funcSlice []string
+	if opts.StacktraceFunctionNames {
+		funcSlice = make([]string, 0, len(stacktrace))
+		for _, caller := range stacktrace {
+			var function = "???"
+			if caller.FunctionName != "" {
+				var _, name = path.Split(caller.FunctionName)
+				function = name + "()"
+			}
+			funcSlice = append(funcSlice, function)
+			if width := VisibleWidth(function); width > maxFuncLen {
+				maxFuncLen = width
+			}
+		}
+	}
+	for i, caller := range stacktrace {
 		var start = startSlice[i]
 		writeIfColorized(b, colorized, start, Italics, DimGrey)
 
@@ -133,25 +324,43 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 
 		writeIfColorized(b, colorized, middle, Italics, Red)
 
-		if len(middle) < maxMiddleLen {
-			b.Grow(maxMiddleLen - len(middle) + 1)
-			for i := 0; i < maxMiddleLen-len(middle); i++ {
+		if width := VisibleWidth(middle); width < maxMiddleLen {
+			b.Grow(maxMiddleLen - width + 1)
+			for i := 0; i < maxMiddleLen-width; i++ {
 				b.WriteString(" ")
 			}
 		}
 		b.WriteString(" ")
 
+		if funcSlice != nil {
+			var function = funcSlice[i]
+			writeIfColorized(b, colorized, function, Italics, Yellow)
+			if width := VisibleWidth(function); width < maxFuncLen {
+				b.Grow(maxFuncLen - width + 1)
+				for i := 0; i < maxFuncLen-width; i++ {
+					b.WriteString(" ")
+				}
+			}
+			b.WriteString(" ")
+		}
+
 		writeIfColorized(b, colorized, CutFrontPath(caller.File, stacktracePathSize), Italics, DimGrey)
 		b.WriteString("\n")
+
+		if opts.SourceContext > 0 {
+			writeSourceContext(b, colorized, caller.File, caller.Line, opts.SourceContext)
+		}
+	}
+
+	if omitted := len(e.Stacktrace) - len(stacktrace); omitted > 0 {
+		writeIfColorized(b, colorized, fmt.Sprintf("... and %d more frames", omitted), Italics, DimGrey)
+		b.WriteString("\n")
 	}
 
 	// max length of a line
-	var maxLen int
-	for _, line := range strings.Split(b.String(), "\n") {
-		line = DeColorize(line)
-		if len(line) > maxLen {
-			maxLen = len(line)
-		}
+	var maxLen = maxLineWidth(b.String())
+	if width > maxLen {
+		maxLen = width
 	}
 
 	// Add a line at the beginning and end of the message.
@@ -160,16 +369,16 @@ func (e *LogEntry) AsString(prefix string, colorized bool) string {
 		b.WriteString("-")
 	}
 	b.WriteString("\n")
-
-	//	var str = b.String()
-	//	b.Reset()
-	//	b.Grow(maxLen + 1 + len(str) + 2)
-	//	for i := 0; i < maxLen; i++ {
-	//		b.WriteString("-")
-	//	}
-	//	b.WriteString("\n")
-	//	b.WriteString(str)
-	//	b.WriteString("\n")
-
 	return b.String()
 }
+
+// maxLineWidth returns the amount of columns taken up by the widest line of s.
+func maxLineWidth(s string) int {
+	var maxLen int
+	for _, line := range strings.Split(s, "\n") {
+		if width := VisibleWidth(line); width > maxLen {
+			maxLen = width
+		}
+	}
+	return maxLen
+}
@@ -0,0 +1,262 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nigel2392/request-logger/accumulator"
+	"github.com/Nigel2392/router/v3/middleware/tracer"
+)
+
+// restoreGlobals restores the package-level settings when the test ends.
+func restoreGlobals(t testing.TB) {
+	var globals = SnapshotGlobals()
+	t.Cleanup(func() { RestoreGlobals(globals) })
+}
+
+// Frames of a fake stacktrace, ordered from the outermost to the innermost call like the tracer.
+func testFrames(n int) tracer.StackTrace {
+	var frames = make(tracer.StackTrace, n)
+	for i := range frames {
+		frames[i] = tracer.Caller{
+			File:         fmt.Sprintf("/src/app/frame%02d.go", i),
+			Line:         i + 1,
+			FunctionName: fmt.Sprintf("app.frame%02d", i),
+		}
+	}
+	return frames
+}
+
+func TestAsStringMaxRenderedFrames(t *testing.T) {
+	var entry = &LogEntry{Level: ERROR, Message: "boom", Stacktrace: testFrames(16)}
+	var out = entry.AsStringWith("", false, RenderOptions{MaxRenderedFrames: 5})
+
+	for i := 0; i < 11; i++ {
+		if strings.Contains(out, fmt.Sprintf("frame%02d.go", i)) {
+			t.Errorf("outer frame %d is rendered:\n%s", i, out)
+		}
+	}
+	for i := 11; i < 16; i++ {
+		if !strings.Contains(out, fmt.Sprintf("frame%02d.go", i)) {
+			t.Errorf("inner frame %d is not rendered:\n%s", i, out)
+		}
+	}
+	var note = strings.Index(out, "... and 11 more frames")
+	if note < 0 {
+		t.Fatalf("missing note about the omitted frames:\n%s", out)
+	}
+	if last := strings.Index(out, "frame15.go"); note < last {
+		t.Errorf("note is written before the frames:\n%s", out)
+	}
+	if ruler := strings.LastIndex(out, "\n-"); note > ruler {
+		t.Errorf("note is written after the ruler:\n%s", out)
+	}
+}
+
+func TestAsStringAllFrames(t *testing.T) {
+	var entry = &LogEntry{Level: ERROR, Message: "boom", Stacktrace: testFrames(16)}
+	var out = entry.AsString("", false)
+	for i := 0; i < 16; i++ {
+		if !strings.Contains(out, fmt.Sprintf("frame%02d.go", i)) {
+			t.Errorf("frame %d is not rendered", i)
+		}
+	}
+	if strings.Contains(out, "more frames") {
+		t.Errorf("unexpected note about omitted frames:\n%s", out)
+	}
+}
+
+func TestLoggerMaxRenderedFrames(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var other, otherBuf = newTestLogger(t, DEBUG)
+	l.MaxRenderedFrames = 1
+	l.Error("boom")
+	other.Error("boom")
+	if !strings.Contains(buf.String(), "more frames") {
+		t.Errorf("frames are not limited by the MaxRenderedFrames of the logger:\n%s", buf.String())
+	}
+	if strings.Contains(otherBuf.String(), "more frames") {
+		t.Errorf("frames of another logger are limited:\n%s", otherBuf.String())
+	}
+}
+
+func TestAccumulatorBySeverity(t *testing.T) {
+	var notice = registerTestLevel(t, "NOTICE", 10, INFO.Severity()-5)
+	var flushed []Loglevel
+	var a = accumulator.NewAccumulator(6, time.Hour, func(entries []*LogEntry) {
+		for _, entry := range entries {
+			flushed = append(flushed, entry.Level)
+		}
+	})
+	defer a.Close()
+	a.Less = BySeverity
+	for _, level := range []Loglevel{DEBUG, CRITICAL, INFO, notice, DEBUG, CRITICAL} {
+		a.Push(&LogEntry{Level: level})
+	}
+
+	var want = []Loglevel{CRITICAL, CRITICAL, notice, INFO, DEBUG, DEBUG}
+	if len(flushed) != len(want) {
+		t.Fatalf("flushed %v, want %v", flushed, want)
+	}
+	for i := range want {
+		if flushed[i] != want[i] {
+			t.Fatalf("flushed %v, want %v", flushed, want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.RelativeTime = true
+	var now = startTime.Add(1500 * time.Millisecond)
+	l.Clock = func() time.Time { return now }
+	l.Info("first")
+	now = now.Add(250 * time.Millisecond)
+	l.Info("second")
+
+	var lines = strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "+1.500s [INFO] first") {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "+1.750s [INFO] second") {
+		t.Errorf("unexpected second line %q", lines[1])
+	}
+
+	var other, otherBuf = newTestLogger(t, DEBUG)
+	other.Info("absolute")
+	if !strings.HasPrefix(otherBuf.String(), "2024-01-02 03:04:05 [INFO] absolute") {
+		t.Errorf("another logger writes a relative time: %q", otherBuf.String())
+	}
+
+	var entry = &LogEntry{Time: now, Level: WARNING, Message: "entry"}
+	if got := entry.AsStringWith("", false, RenderOptions{RelativeTime: true}); !strings.HasPrefix(got, "+1.750s [ WARNING ] - entry") {
+		t.Errorf("unexpected AsString %q", got)
+	}
+}
+
+func TestStacktraceFunctionNames(t *testing.T) {
+	var frames = tracer.StackTrace{
+		{File: "/src/app/a.go", Line: 1, FunctionName: "app.short"},
+		{File: "/src/app/b.go", Line: 200, FunctionName: "github.com/org/app/pkg.(*Server).longerName"},
+	}
+	var entry = &LogEntry{Time: testTime, Level: ERROR, Message: "boom", Stacktrace: frames}
+	var out = entry.AsStringWith("", false, RenderOptions{StacktraceFunctionNames: true})
+
+	var columns []int
+	for _, name := range []string{"app.short()", "pkg.(*Server).longerName()"} {
+		var line = lineContaining(out, name)
+		if line == "" {
+			t.Fatalf("missing function name %s:\n%s", name, out)
+		}
+		columns = append(columns, strings.Index(line, name))
+	}
+	if columns[0] != columns[1] {
+		t.Errorf("function names start at columns %v, want them aligned:\n%s", columns, out)
+	}
+	if out := entry.AsString("", false); strings.Contains(out, "app.short()") {
+		t.Errorf("function names are rendered without the option:\n%s", out)
+	}
+}
+
+// lineContaining returns the first line of s which contains substr.
+func lineContaining(s, substr string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.Contains(line, substr) {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestAsStringEmptyStacktrace(t *testing.T) {
+	restoreGlobals(t)
+	for name, trace := range map[string]tracer.StackTrace{"nil": nil, "empty": {}} {
+		var e = &LogEntry{Time: time.Now(), Level: ERROR, Message: "boom", Stacktrace: trace}
+		var out = e.AsString("", false)
+		if strings.Contains(out, "Stacktrace:") || strings.Contains(out, "---") {
+			t.Errorf("%s stacktrace renders a header or separator:\n%s", name, out)
+		}
+		if !strings.HasSuffix(out, "boom\n") {
+			t.Errorf("%s stacktrace: unexpected output %q", name, out)
+		}
+	}
+}
+
+func TestQuoteMessage(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.QuoteMessage = true
+	l.Info(`value] - said "hi"` + "\nnext")
+	if want := `2024-01-02 03:04:05 [INFO] "value] - said \"hi\"\nnext"` + "\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	var e = &LogEntry{Time: testTime, Level: ERROR, Message: `failed]`, Stacktrace: testFrames(2)}
+	var out = e.AsStringWith("", false, l.RenderOptions)
+	if !strings.HasPrefix(out, `2024-01-02 03:04:05 [ ERROR ] - "failed]"`+"\n") {
+		t.Errorf("unexpected first line:\n%s", out)
+	}
+	if strings.Count(out, `"`) != 2 || !strings.Contains(out, "Stacktrace:") {
+		t.Errorf("stacktrace is not written outside of the quotes:\n%s", out)
+	}
+}
+
+func TestAsStringSixteenFrames(t *testing.T) {
+	restoreGlobals(t)
+	var e = &LogEntry{Time: testTime, Level: ERROR, Message: "boom", Stacktrace: testFrames(16)}
+
+	var want = &strings.Builder{}
+	want.WriteString("2024-01-02 03:04:05 [ app ERROR ] - boom\n\nStacktrace:\n")
+	for i := 0; i < 16; i++ {
+		fmt.Fprintf(want, "%-17s frame%02d.go() /src/app/frame%02d.go\n", fmt.Sprintf("Error on line %d:", i+1), i, i)
+	}
+	want.WriteString(strings.Repeat("-", 50) + "\n")
+
+	var out = e.AsString("app ", false)
+	if out != want.String() {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want.String())
+	}
+	if colored := e.AsString("app ", true); DeColorize(colored) != out {
+		t.Errorf("colored output differs from the uncolored output:\n%s", DeColorize(colored))
+	}
+}
+
+func BenchmarkAsString(b *testing.B) {
+	var e = &LogEntry{Time: testTime, Level: ERROR, Message: "boom", Stacktrace: testFrames(16)}
+	for _, colorized := range []bool{false, true} {
+		b.Run(fmt.Sprintf("colorized=%t", colorized), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				e.AsString("app ", colorized)
+			}
+		})
+	}
+}
+
+func TestAsStringMultibyteAlignment(t *testing.T) {
+	restoreGlobals(t)
+	var frames = testFrames(3)
+	frames[1].File = "/src/app/日本語.go"
+	frames[2].File = "/src/app/ünïcödé.go"
+	var e = &LogEntry{Time: testTime, Level: ERROR, Message: "boom", Stacktrace: frames}
+	var out = e.AsString("", false)
+
+	// The paths start in the same column on every frame line.
+	var column = -1
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "Error on line") {
+			continue
+		}
+		var i = strings.Index(line, "/src/app/")
+		var width = VisibleWidth(line[:i])
+		if column == -1 {
+			column = width
+		} else if width != column {
+			t.Errorf("path of %q starts at column %d, want %d:\n%s", line, width, column, out)
+		}
+	}
+	if column == -1 {
+		t.Fatalf("no frames were rendered:\n%s", out)
+	}
+}
@@ -1,13 +1,17 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/Nigel2392/router/v3/middleware/tracer"
 	"github.com/Nigel2392/router/v3/request"
 )
 
@@ -24,16 +28,175 @@ func NewLogFile(filename string) (*os.File, error) {
 	return file, err
 }
 
+// FallbackWriter is written to by loggers which have no writer set.
+//
+// Set this to io.Discard to drop the output of these loggers instead.
+var FallbackWriter io.Writer = os.Stderr
+
+// Logger writes lines to its writer, it is safe for concurrent use.
+//
+// Loggers should be created with NewLogger. The zero value can be used too, but all zero value loggers
+// share their write mutex and trackers, so muting or capturing one of them affects the others.
 type Logger struct {
 	Loglevel Loglevel
 	prefix   string
 	File     io.Writer
+
+	// LevelOffset shifts the Loglevel by a number of levels, without changing it.
+	//
+	// A positive offset makes the logger quieter, an offset of 1 on an INFO logger only writes warnings and above.
+	// A negative offset makes it more verbose.
+	LevelOffset int
+
+	// Formatter formats the lines of the logger, such as a JSONFormatter for log shippers.
+	//
+	// If nil, lines are written as human readable text.
+	Formatter Formatter
+
+	// Colorized writes lines with ANSI color codes, this is enabled by NewLogger.
+	//
+	// Disable it when the output is not a terminal, such as a file or journald.
+	// The uncolored output is the same as the colored output with the color codes removed.
+	Colorized bool
+
+	// SanitizeInput escapes control characters and ANSI escape sequences in messages.
+	//
+	// This prevents user input in messages from manipulating the terminal or forging log lines.
+	SanitizeInput bool
+
+	// RenderOptions are the settings for rendering the lines of the logger as text, such as the LineTerminator.
+	//
+	// They do not apply to the lines of a Formatter.
+	RenderOptions
+
+	// AutoComponent adds the name of the package which called the logger to every line,
+	// as the "component" field.
+	AutoComponent bool
+
+	// IncludeCallerFunc adds the fully qualified name of the function which called the logger to every line,
+	// as the "caller_func" field, such as "github.com/user/pkg.(*Type).Method".
+	IncludeCallerFunc bool
+
+	// Uptime adds the time elapsed since the process started to every line, as the "uptime" field.
+	//
+	// The uptime is measured with the monotonic clock, so it is not affected by changes to the wall-clock time.
+	Uptime bool
+
+	// Clock returns the current time, used as the time of new lines, defaults to time.Now.
+	//
+	// It can be replaced to control the time, for example in tests.
+	Clock func() time.Time
+
+	// MuteKeepErrors keeps writing errors and critical messages while the logger is muted, see Mute.
+	MuteKeepErrors bool
+
+	// ColorFunc returns the color of the prefix of a line, from the log entry of the line.
+	//
+	// This can be used to color lines by any attribute, such as a field. Defaults to the color of the loglevel.
+	ColorFunc func(entry *LogEntry) Color
+
+	// LogEntrySize adds the size of every line in bytes, as the "size" field.
+	//
+	// The size is that of the written line without colors, including the size field itself.
+	// It is not added to lines of a Formatter.
+	LogEntrySize bool
+
+	// OnEntry is called with every log entry which is written,
+	// including all structured fields of the line.
+	//
+	// This can be used to forward fields to another system, such as metrics.
+	OnEntry func(entry *LogEntry)
+
+	// OnSinkError is called with a sink set with SetSinks which failed to write a line, and the error of the write.
+	//
+	// The line is still written to the other sinks.
+	OnSinkError func(sink Sink, err error)
+
+	// AuditWriter receives the events written with Audit, separate from the other log lines.
+	//
+	// If nil, audit events are written to the logger's own writer.
+	AuditWriter io.Writer
+
+	// AuditFormatter formats the events written with Audit, defaults to a JSONFormatter.
+	//
+	// If it is a HeaderFormatter, its header is written before the first audit event.
+	AuditFormatter Formatter
+
+	// VolumeWarnThreshold is the amount of lines per second above which
+	// a warning about the log volume is written, to notice log storms.
+	//
+	// A value <= 0 disables the warning.
+	VolumeWarnThreshold int
+
+	// The minimum time between two warnings about the log volume, defaults to a minute.
+	VolumeWarnWindow time.Duration
+
+	// StacktraceDepth is the maximum amount of frames captured for the stacktrace of errors, defaults to 16.
+	StacktraceDepth int
+
+	// RepeatWindow summarizes an error passed to Critical, Criticalf, Error or Errorf which is the same as the previous one,
+	// within the window since its stacktrace was written. Only the first occurrence gets a stacktrace.
+	//
+	// A value <= 0 writes the stacktrace of every error.
+	RepeatWindow time.Duration
+
+	// ProgressStep is the increase of the progress since the last line, at which Progress writes a new line
+	// when not writing to a terminal. Defaults to 0.1, a line per 10%.
+	ProgressStep float64
+
+	// RequestSeparator writes a dimmed rule between requests served by a handler wrapped with SeparateRequests.
+	//
+	// The rule is not written when the logger is not colorized or has a Formatter.
+	RequestSeparator bool
+
+	// DumpMaxDepth is the maximum depth of the structs, slices, arrays and maps written by Dump, defaults to 8.
+	DumpMaxDepth int
+
+	// TableKeysRight aligns the keys of Table to the right, against the values.
+	//
+	// By default the keys are aligned to the left.
+	TableKeysRight bool
+
+	// MaxTotalBytes is the maximum amount of bytes the logger writes, lines over it are dropped.
+	//
+	// A notice is written once, when the first line is dropped.
+	// The last tenth is kept for lines of loglevel error and above, so errors are dropped last.
+	// A value <= 0 does not limit the output.
+	MaxTotalBytes int64
+
+	// StrictFields panics when a field required by RequireFields is missing from a line,
+	// instead of writing a warning.
+	StrictFields bool
+
+	// Fields which are added to every line.
+	fields Fields
+
+	// The state shared with copies of the logger, such as the mutex which serializes the writes.
+	state *loggerState
+
+	// The state of this logger which is not shared with the loggers derived from it,
+	// such as its mutes, captures and subscribers.
+	scope *loggerScope
+
+	// A dimmed context segment which is written after the message, see LogKV.
+	context string
+
+	// Regular expressions of which the matches in messages are colorized.
+	highlights []highlight
+
+	// Keys of the fields which must be present on every line,
+	// and the keys which a missing-field warning has been written for.
+	requiredFields []string
+	warnedFields   *sync.Map
 }
 
 func NewLogger(loglevel Loglevel, w io.Writer, prefix ...string) *Logger {
 	var l = Logger{
-		Loglevel: loglevel,
-		File:     w,
+		Loglevel:  loglevel,
+		File:      w,
+		Colorized: true,
+		state:     newLoggerState(),
+		scope:     newLoggerScope(nil),
 	}
 	if len(prefix) > 0 {
 		l.prefix = prefix[0]
@@ -41,26 +204,120 @@ func NewLogger(loglevel Loglevel, w io.Writer, prefix ...string) *Logger {
 	return &l
 }
 
-func (l *Logger) Critical(err error) {
-	var t = tracer.TraceSafe(err, 16, 1)
-	l.logLine(CRITICAL, err.Error())
-	for _, i := range t.Trace() {
-		l.logLine(CRITICAL, fmt.Sprintf("%s:%d", i.File, i.Line))
+// WithColor returns a copy of the logger which writes lines with or without ANSI color codes.
+//
+// The copy shares the writer of the logger.
+func (l *Logger) WithColor(colorized bool) *Logger {
+	var clone = l.derive()
+	clone.Colorized = colorized
+	return clone
+}
+
+// NewJSONLogger creates a new Logger which writes every line as a JSON object, see JSONFormatter.
+func NewJSONLogger(loglevel Loglevel, w io.Writer) *Logger {
+	var l = NewLogger(loglevel, w)
+	l.Formatter = &JSONFormatter{}
+	l.Colorized = false
+	return l
+}
+
+// RequireFields sets the keys of fields which must be present on every line.
+//
+// When a required field is missing, a warning is written once per key.
+// When running under "go test", StrictFields is enabled so a missing field panics instead.
+func (l *Logger) RequireFields(keys ...string) {
+	l.requiredFields = keys
+	l.warnedFields = &sync.Map{}
+	l.StrictFields = isTestBinary()
+}
+
+// checkRequiredFields warns about, or panics on, required fields which are missing.
+func (l *Logger) checkRequiredFields(t time.Time, fields Fields) {
+	for _, key := range l.requiredFields {
+		if _, ok := fields.Get(key); ok {
+			continue
+		}
+		if l.StrictFields {
+			panic(fmt.Sprintf("logger: required field %q is missing", key))
+		}
+		if _, warned := l.warnedFields.LoadOrStore(key, struct{}{}); !warned {
+			l.writeRaw(t, WARNING, l.render(t, WARNING, fmt.Sprintf("required field %q is missing\n", key)), nil, false)
+		}
 	}
 }
 
+// AddHighlight colorizes all matches of the regular expression in messages.
+//
+// When matches of highlights overlap, the highlight which was added first wins.
+func (l *Logger) AddHighlight(re *regexp.Regexp, color Color) {
+	l.highlights = append(l.highlights[:len(l.highlights):len(l.highlights)], highlight{re, color})
+}
+
+// ForSpan returns a copy of the logger which tags every line with the trace and span ID.
+//
+// The copy shares the writer of the logger.
+func (l *Logger) ForSpan(traceID, spanID string) *Logger {
+	return l.WithFields(NewFields("trace_id", traceID, "span_id", spanID))
+}
+
+// WithFields returns a copy of the logger which adds the fields to every line.
+//
+// The fields are added after the fields of the logger, replacing fields with the same key.
+func (l *Logger) WithFields(fields Fields) *Logger {
+	var clone = l.derive()
+	clone.fields = l.fields.Merge(fields)
+	return clone
+}
+
+func (l *Logger) Critical(err error) {
+	l.logCritical(err, 1)
+}
+
+// Write a critical message, loglevel critical, including the stacktrace of the call.
 func (l *Logger) Criticalf(format string, args ...any) {
-	l.log(CRITICAL, fmt.Sprintf(format, args...))
+	l.logError(CRITICAL, fmt.Errorf(format, args...), 1)
 }
 
-// Write an error message, loglevel error
+// Write an error message, loglevel error, including the stacktrace of the call.
 func (l *Logger) Error(args ...any) {
-	l.logLine(ERROR, fmt.Sprint(args...))
+	l.logErrorArgs(args, 1)
 }
 
-// Write an error message, loglevel error
+// Write an error message, loglevel error, including the stacktrace of the call.
 func (l *Logger) Errorf(format string, args ...any) {
-	l.log(ERROR, fmt.Sprintf(format, args...))
+	l.logError(ERROR, fmt.Errorf(format, args...), 1)
+}
+
+// logCritical writes the error like Critical.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logCritical.
+func (l *Logger) logCritical(err error, skip int) {
+	if isBenign(err) {
+		l.logLine(CRITICAL, err.Error())
+		return
+	}
+	l.logError(CRITICAL, err, skip+1)
+}
+
+// logErrorArgs writes the arguments like Error.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logErrorArgs.
+func (l *Logger) logErrorArgs(args []any, skip int) {
+	if benignArgs(args) {
+		l.logLine(ERROR, fmt.Sprint(args...))
+		return
+	}
+	l.logError(ERROR, errors.New(fmt.Sprint(args...)), skip+1)
+}
+
+// logError writes the error with the stacktrace of the call, unless it is a repeat, see RepeatWindow.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logError.
+func (l *Logger) logError(level Loglevel, err error, skip int) {
+	if l.logRepeat(level, err) {
+		return
+	}
+	l.logTrace(level, err, skip+1)
 }
 
 // Write a warning message, loglevel warning
@@ -103,32 +360,473 @@ func (l *Logger) Testf(format string, args ...any) {
 	l.log(TEST, fmt.Sprintf(format, args...))
 }
 
+// LogAt writes a message with the given loglevel, at the given time instead of the current time.
+//
+// This is useful when replaying historical events.
+func (l *Logger) LogAt(t time.Time, level Loglevel, args ...any) {
+	l.WriteEntry(&LogEntry{
+		Time:    t,
+		Level:   level,
+		Message: fmt.Sprint(args...),
+	})
+}
+
+// WriteEntry writes the log entry, with the time and level of the entry.
+//
+// The stacktrace of the entry is written under the message, like the stacktrace of an error.
+func (l *Logger) WriteEntry(entry *LogEntry) {
+	if !l.enabled(entry.Level) {
+		return
+	}
+	var e = *entry
+	l.logEntry(&e)
+}
+
+// InfoKV writes an info message, followed by a dimmed context segment of key=value pairs.
+//
+//	l.InfoKV("synchronizing", "op", "sync", "user", 42)
+//
+// The pairs must come in twos, a key without a value is written as key=<missing>.
+func (l *Logger) InfoKV(msg string, kv ...any) {
+	l.LogKV(INFO, msg, kv...)
+}
+
+// LogKV writes a message with the given loglevel, followed by a dimmed context segment of key=value pairs.
+//
+// The pairs must come in twos, a key without a value is written as key=<missing>.
+func (l *Logger) LogKV(level Loglevel, msg string, kv ...any) {
+	if !l.enabled(level) {
+		return
+	}
+	if len(kv) == 0 {
+		l.logLine(level, msg)
+		return
+	}
+	var b = &strings.Builder{}
+	for i := 0; i < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprint(b, kv[i])
+		b.WriteString("=")
+		if i+1 < len(kv) {
+			fmt.Fprint(b, kv[i+1])
+		} else {
+			b.WriteString("<missing>")
+		}
+	}
+	var clone = *l
+	clone.context = b.String()
+	clone.logLine(level, msg)
+}
+
+// PrintLegend writes a line showing the name of each loglevel in its color.
+//
+// This helps readers of the output to know which color means which loglevel.
+// Nothing is written if the logger has a Formatter.
+func (l *Logger) PrintLegend() {
+	if l.Formatter != nil {
+		return
+	}
+	l.writeLine(l.now(), INFO, terminateLines(legend(l.Colorized), l.LineTerminator), nil)
+}
+
+// legend returns the legend line, with the levels from least to most severe.
+func legend(colorized bool) string {
+	var b = &strings.Builder{}
+	b.WriteString("Legend:")
+	for level := TEST; level >= CRITICAL; level-- {
+		b.WriteString(" ")
+		writeIfColorized(b, colorized, level.String(), getLogLevelColor(level))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Timed returns a function which writes the time elapsed since Timed was called.
+//
+// This is meant to time a function with defer:
+//
+//	defer l.Timed(logger.INFO, "handler")()
+func (l *Logger) Timed(level Loglevel, label string) func() {
+	var start = l.now()
+	return func() {
+		var elapsed = l.now().Sub(start)
+		l.WithFields(NewFields("label", label, "duration", elapsed)).
+			logLine(level, fmt.Sprintf("%s took %s", label, elapsed))
+	}
+}
+
+// Audit writes an audit event, such as a login or permission change, to the AuditWriter.
+//
+// Audit events are always written, regardless of the loglevel of the logger.
+func (l *Logger) Audit(event string, fields Fields) {
+	var w = l.AuditWriter
+	if w == nil {
+		w = l.writer()
+	}
+	var f = l.AuditFormatter
+	if f == nil {
+		f = &JSONFormatter{}
+	}
+	var b = f.Format(&LogEntry{
+		Time:    l.now(),
+		Level:   INFO,
+		Message: event,
+		Fields:  l.fields.Merge(fields),
+	})
+	var s = l.shared()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.auditHeader.Do(func() { WriteHeader(w, f) })
+	w.Write(b)
+}
+
 func (l *Logger) LogLevel() request.LogLevel {
-	return request.LogLevel(l.Loglevel)
+	return request.LogLevel(l.effectiveLevel())
+}
+
+// effectiveLevel returns the loglevel of the logger, shifted by the LevelOffset.
+func (l *Logger) effectiveLevel() Loglevel {
+	return l.Loglevel - Loglevel(l.LevelOffset)
+}
+
+// enabled reports whether a message with the given level is written by the logger.
+func (l *Logger) enabled(level Loglevel) bool {
+	if l.ownScope().muted() && !(l.MuteKeepErrors && level.AtLeast(ERROR)) {
+		return false
+	}
+	return allowLevel(l.effectiveLevel(), level)
 }
 
 func (l *Logger) logLine(level Loglevel, msg string) {
-	l.log(level, msg+"\n")
+	l.log(level, lineOf(msg))
 }
 
 func (l *Logger) log(msgType Loglevel, msg string) {
-	if l.Loglevel >= Loglevel(msgType) {
-		fmt.Fprintf(l.File, "%s%s", generatePrefix(true, l.prefix, msgType), msg)
+	l.logAt(l.now(), msgType, msg)
+}
+
+func (l *Logger) logAt(t time.Time, msgType Loglevel, msg string) {
+	if !l.enabled(msgType) {
+		return
+	}
+	var fields = l.lineFields()
+	if len(l.requiredFields) > 0 {
+		l.checkRequiredFields(t, fields)
+	}
+	var line string
+	if l.LogEntrySize && l.Formatter == nil {
+		line, fields = withSize(fields, func(fields Fields) string {
+			return l.renderFields(t, msgType, msg, fields)
+		})
+	} else {
+		line = l.renderFields(t, msgType, msg, fields)
+	}
+	var entry *LogEntry
+	if l.wantsEntry() {
+		entry = &LogEntry{
+			Time:    t,
+			Level:   msgType,
+			Message: strings.TrimRight(msg, "\r\n"),
+			Fields:  fields,
+		}
+	}
+	l.writeLine(t, msgType, line, entry)
+}
+
+// logTrace writes the error with the stacktrace of the call under the message.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of logTrace.
+func (l *Logger) logTrace(level Loglevel, err error, skip int) {
+	if !l.enabled(level) {
+		return
+	}
+	l.logEntry(l.traceEntry(level, err, skip+1))
+}
+
+// traceEntry returns an entry with the error as message, and the stacktrace of the call up to the StacktraceDepth.
+//
+// skip is the number of frames to skip in the stacktrace, above the caller of traceEntry.
+func (l *Logger) traceEntry(level Loglevel, err error, skip int) *LogEntry {
+	var depth = l.StacktraceDepth
+	if depth <= 0 {
+		depth = 16
+	}
+	var trace, ok = captureTrace(true, err, depth, skip+1)
+	var message = err.Error()
+	if !ok {
+		message += " " + stacktraceUnavailable
+	}
+	return &LogEntry{
+		Time:       l.now(),
+		Level:      level,
+		Message:    message,
+		Stacktrace: trace,
+	}
+}
+
+// logEntry writes the entry with the fields of the logger, and its stacktrace under the message.
+//
+// The fields of the entry are added after the fields of the logger.
+func (l *Logger) logEntry(entry *LogEntry) {
+	entry.Message = strings.TrimRight(entry.Message, "\r\n")
+	entry.Fields = l.lineFieldsWith(entry.Fields)
+	if len(l.requiredFields) > 0 {
+		l.checkRequiredFields(entry.Time, entry.Fields)
+	}
+	var line string
+	if l.LogEntrySize && l.Formatter == nil {
+		var rendered = *entry
+		line, entry.Fields = withSize(entry.Fields, func(fields Fields) string {
+			rendered.Fields = fields
+			return l.renderEntry(&rendered)
+		})
+	} else {
+		line = l.renderEntry(entry)
+	}
+	l.writeLine(entry.Time, entry.Level, line, entry)
+}
+
+// withSize renders a line with its size as the "size" field, see LogEntrySize.
+//
+// The size field can change the width of the line, such as the ruler of a stacktrace,
+// so the line is rendered until the size is stable.
+func withSize(fields Fields, render func(Fields) string) (string, Fields) {
+	var line = render(fields)
+	var size = lineSize(line)
+	var sized Fields
+	for i := 0; i < 4; i++ {
+		sized = fields.With("size", size)
+		line = render(sized)
+		if len(DeColorize(line)) == size {
+			break
+		}
+		size = len(DeColorize(line))
+	}
+	return line, sized
+}
+
+// writeLine writes a rendered line, and passes the entry of the line to the OnEntry hook and subscribers if it is not nil.
+//
+// Everything the logger writes goes through writeLine, or writeRaw for progress lines.
+func (l *Logger) writeLine(t time.Time, level Loglevel, line string, entry *LogEntry) {
+	if !l.writeRaw(t, level, line, entry, false) {
+		return
+	}
+	if l.VolumeWarnThreshold > 0 {
+		l.trackVolume(t)
+	}
+	if entry == nil {
+		return
+	}
+	l.ownScope().publish(entry)
+	if l.OnEntry != nil {
+		l.OnEntry(entry)
+	}
+}
+
+// writeRaw writes a rendered line to the writer of the logger, and reports whether it was written.
+//
+// All output of the logger goes through here, so the MaxTotalBytes applies to every line.
+// A progress line replaces the active progress line, other lines end it first.
+// The entry of the line is written to the sinks with a Formatter, it is nil for lines without an entry.
+func (l *Logger) writeRaw(t time.Time, level Loglevel, line string, entry *LogEntry, progress bool) bool {
+	if l.MaxTotalBytes > 0 && !l.capOutput(t, level, len(line)) {
+		return false
+	}
+	l.put(level, line, entry, progress)
+	return true
+}
+
+// put writes the line as a whole, without interleaving with other lines, and adds it to the running captures
+// of the logger and the loggers it was derived from.
+//
+// If sinks are set with SetSinks, the line or entry is written to the sinks which allow its loglevel instead of the writer.
+func (l *Logger) put(level Loglevel, line string, entry *LogEntry, progress bool) {
+	var s = l.shared()
+	s.mutex.Lock()
+	if progress {
+		s.progress.setActive(!strings.HasSuffix(line, l.lineTerminator()))
+	} else {
+		s.progress.interrupt(l.writer(), l.lineTerminator())
+	}
+	var failed []sinkError
+	if len(s.sinks) == 0 {
+		io.WriteString(l.writer(), line)
+	} else {
+		failed = writeSinks(s.sinks, level, []byte(line), entry)
+	}
+	s.mutex.Unlock()
+	l.ownScope().capture(line)
+	if l.OnSinkError != nil {
+		for _, f := range failed {
+			l.OnSinkError(f.sink, f.err)
+		}
+	}
+}
+
+// wantsEntry reports whether the log entry of a line is used, by the OnEntry hook, a subscriber or a sink with a Formatter.
+func (l *Logger) wantsEntry() bool {
+	return l.OnEntry != nil || l.ownScope().subscribed() || l.shared().formattedSinks.Load()
+}
+
+// render renders the message as it is written by the logger.
+func (l *Logger) render(t time.Time, msgType Loglevel, msg string) string {
+	return l.renderFields(t, msgType, msg, l.lineFields())
+}
+
+// renderEntry renders the entry as it is written by the logger, with its stacktrace under the message.
+//
+// The line is rendered like every other line of the logger rather than with AsString,
+// so errors keep the prefix, fields and settings of the logger. The stacktrace is written by the entry, like AsString does.
+func (l *Logger) renderEntry(entry *LogEntry) string {
+	if l.Formatter != nil {
+		var e = *entry
+		if l.context != "" {
+			e.Message += "  " + l.context
+		}
+		return string(l.Formatter.Format(&e))
+	}
+	return entry.withStacktrace(l.renderFields(entry.Time, entry.Level, lineOf(entry.Message), entry.Fields), l.Colorized, l.RenderOptions)
+}
+
+func (l *Logger) renderFields(t time.Time, msgType Loglevel, msg string, fields Fields) string {
+	if l.Formatter != nil {
+		return l.renderEntry(&LogEntry{
+			Time:    t,
+			Level:   msgType,
+			Message: strings.TrimRight(msg, "\r\n"),
+			Fields:  fields,
+		})
+	}
+	if l.SanitizeInput {
+		msg = Sanitize(msg)
+	}
+	if l.QuoteMessage {
+		var trimmed = strings.TrimRight(msg, "\r\n")
+		msg = strconv.Quote(trimmed) + msg[len(trimmed):]
+	}
+	if len(l.highlights) > 0 && l.Colorized {
+		msg = applyHighlights(msg, l.highlights)
+	}
+	if l.context != "" {
+		var context = l.context
+		if l.SanitizeInput {
+			context = Sanitize(context)
+		}
+		var trimmed = strings.TrimRight(msg, "\r\n")
+		var b = &strings.Builder{}
+		b.WriteString(trimmed)
+		b.WriteString("  ")
+		writeIfColorized(b, l.Colorized, context, DimGrey)
+		b.WriteString(msg[len(trimmed):])
+		msg = b.String()
+	}
+	var color Color
+	if l.Colorized {
+		color = l.lineColor(t, msgType, msg, fields)
+	}
+	return generatePrefix(color, l.prefix, msgType, t, l.RenderOptions) + terminateLines(appendFields(msg, fields), l.LineTerminator)
+}
+
+// lineColor returns the color of the prefix of a line, see ColorFunc.
+func (l *Logger) lineColor(t time.Time, msgType Loglevel, msg string, fields Fields) Color {
+	if l.ColorFunc == nil {
+		return getLogLevelColor(msgType)
+	}
+	return l.ColorFunc(&LogEntry{
+		Time:    t,
+		Level:   msgType,
+		Message: strings.TrimRight(msg, "\r\n"),
+		Fields:  fields,
+	})
+}
+
+// lineFields returns the fields which are added to a line of the logger.
+func (l *Logger) lineFields() Fields {
+	return l.lineFieldsWith(nil)
+}
+
+// lineFieldsWith returns the fields which are added to a line of the logger, with the fields of the line.
+func (l *Logger) lineFieldsWith(extra Fields) Fields {
+	var fields = l.fields.Merge(extra)
+	if l.AutoComponent {
+		fields = fields.With("component", callerComponent())
+	}
+	if l.IncludeCallerFunc {
+		fields = fields.With("caller_func", callerFunc())
+	}
+	if l.Uptime {
+		fields = fields.With("uptime", time.Since(startTime))
+	}
+	return fields
+}
+
+// writer returns the writer of the logger, or the FallbackWriter if it is nil.
+func (l *Logger) writer() io.Writer {
+	if l.File == nil {
+		return FallbackWriter
+	}
+	return l.File
+}
+
+// lineSize returns the size of the line without colors, after a " size=N" field is added to it.
+func lineSize(line string) int {
+	var size = len(DeColorize(line)) + len(" size=")
+	// The size includes its own digits.
+	for digits := 1; ; digits++ {
+		if len(strconv.Itoa(size+digits)) == digits {
+			return size + digits
+		}
 	}
 }
 
-func generatePrefix(colorized bool, prefix string, level Loglevel) string {
-	var msg string
-	msg = "[%s%s] "
-	msg = fmt.Sprintf(msg, prefix, level.String())
-	msg = timestamp(msg)
-	if colorized {
-		var color = getLogLevelColor(level)
-		msg = Colorize(msg, color)
+// lineOf returns the message ending with a newline.
+func lineOf(msg string) string {
+	if !strings.HasSuffix(msg, "\n") {
+		return msg + "\n"
 	}
 	return msg
 }
 
-func timestamp(msg string) string {
-	return fmt.Sprintf("%s %s", time.Now().Format("2006-01-02 15:04:05"), msg)
+// Buffers used to build line prefixes, this avoids allocating a new buffer for every line.
+var prefixPool = sync.Pool{
+	New: func() any {
+		var b = make([]byte, 0, 64)
+		return &b
+	},
+}
+
+// now returns the current time from the Clock of the logger.
+func (l *Logger) now() time.Time {
+	if l.Clock == nil {
+		return time.Now()
+	}
+	return l.Clock()
+}
+
+// generatePrefix returns the prefix of a line, colorized with the color if it is not empty.
+func generatePrefix(color Color, prefix string, level Loglevel, t time.Time, opts RenderOptions) string {
+	if opts.MaxPrefixWidth > 0 && VisibleWidth(prefix) > opts.MaxPrefixWidth {
+		// Keep the separator between the prefix and the level.
+		var trimmed = strings.TrimRight(prefix, " ")
+		var separator = prefix[len(trimmed):]
+		prefix = CutEnd(trimmed, opts.MaxPrefixWidth-len(separator)) + separator
+	}
+	var buf = prefixPool.Get().(*[]byte)
+	var b = (*buf)[:0]
+	b = append(b, color...)
+	b = opts.appendTime(b, t)
+	b = append(b, " ["...)
+	b = append(b, prefix...)
+	b = append(b, level.String()...)
+	b = append(b, "] "...)
+	if color != "" {
+		b = append(b, Reset...)
+	}
+	var msg = string(b)
+	*buf = b
+	prefixPool.Put(buf)
+	return msg
 }
@@ -0,0 +1,583 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The time of the lines written in tests.
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newTestLogger returns an uncolored logger writing to the returned buffer, with a fixed Clock.
+func newTestLogger(t testing.TB, level Loglevel, prefix ...string) (*Logger, *bytes.Buffer) {
+	restoreGlobals(t)
+	var buf = &bytes.Buffer{}
+	var l = NewLogger(level, buf, prefix...)
+	l.Colorized = false
+	l.Clock = func() time.Time { return testTime }
+	return l, buf
+}
+
+// innermostFrame returns the last frame line of the stacktrace in the output.
+func innermostFrame(out string) string {
+	var frame string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Error on line") {
+			frame = line
+		}
+	}
+	return frame
+}
+
+// Reports whether the output has a stacktrace block of which the innermost frame is in the test file.
+func hasTestTrace(out, file string) bool {
+	return strings.Contains(out, "\n\nStacktrace:\n") && strings.Contains(innermostFrame(out), file)
+}
+
+func TestErrorUsesLinePrefix(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG, "a-very-long-prefix ")
+	l.MaxPrefixWidth = 10
+	l.Info("hello")
+	l.Error(errors.New("boom"))
+
+	var lines = strings.Split(buf.String(), "\n")
+	if lines[0] != "2024-01-02 03:04:05 [a-very-l… INFO] hello" {
+		t.Errorf("unexpected info line %q", lines[0])
+	}
+	if lines[1] != "2024-01-02 03:04:05 [a-very-l… ERROR] boom" {
+		t.Errorf("unexpected error line %q", lines[1])
+	}
+	if !hasTestTrace(buf.String(), "logger_test.go") {
+		t.Errorf("missing stacktrace of the call:\n%s", buf.String())
+	}
+}
+
+func TestErrorColorFunc(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Colorized = true
+	l.ColorFunc = func(entry *LogEntry) Color { return Cyan }
+	l.Error("boom")
+	if !strings.HasPrefix(buf.String(), Cyan) {
+		t.Errorf("error line is not colored by the ColorFunc: %q", buf.String())
+	}
+}
+
+func TestStacktraceDepth(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.StacktraceDepth = 2
+	l.Error("boom")
+	if n := strings.Count(buf.String(), "Error on line"); n != 2 {
+		t.Errorf("expected 2 frames, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestTraceWritePaths(t *testing.T) {
+	var tests = map[string]func(l *Logger){
+		"LogOpts": func(l *Logger) {
+			l.LogOpts(INFO, "message", WithStack())
+		},
+		"BufferingLogger": func(l *Logger) {
+			var b = NewBufferingLogger(l)
+			b.Errorf("message")
+			b.Flush()
+		},
+		"LogGroup": func(l *Logger) {
+			var g = l.Group("group")
+			g.Error("message")
+			g.End()
+		},
+	}
+	for name, write := range tests {
+		t.Run(name, func(t *testing.T) {
+			var l, buf = newTestLogger(t, DEBUG)
+			write(l)
+			var out = buf.String()
+			if !strings.Contains(out, "] message\n") {
+				t.Errorf("missing message line:\n%s", out)
+			}
+			if !hasTestTrace(out, "logger_test.go") {
+				t.Errorf("missing stacktrace block:\n%s", out)
+			}
+			if strings.Contains(out, ".go:") {
+				t.Errorf("stacktrace written as raw file:line lines:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestWriteEntry(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.WriteEntry(&LogEntry{
+		Time:       testTime,
+		Level:      WARNING,
+		Message:    "entry",
+		Stacktrace: testFrames(3),
+		Fields:     NewFields("key", 1),
+	})
+	var out = buf.String()
+	if !strings.HasPrefix(out, "2024-01-02 03:04:05 [WARNING] entry key=1\n") {
+		t.Errorf("unexpected first line:\n%s", out)
+	}
+	if strings.Count(out, "Error on line") != 3 || strings.Count(out, "[WARNING]") != 1 {
+		t.Errorf("stacktrace is not written as a single block:\n%s", out)
+	}
+}
+
+func TestFormatterAllWritePaths(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Formatter = &JSONFormatter{}
+	IsTerminal = func(io.Writer) bool { return true }
+
+	l.Banner("app", "v1.0.0", "abcdef")
+	l.PrintLegend()
+	l.Progress("upload", 0.5)
+	l.Progress("upload", 1)
+	var g = l.Group("group")
+	g.Info("in group")
+	g.End()
+	l.Error(errors.New("boom"))
+
+	var lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Errorf("wrote %d lines, want 6:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("line is not valid JSON: %q", line)
+		}
+	}
+}
+
+func TestLineTerminatorCRLF(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var other, otherBuf = newTestLogger(t, DEBUG)
+	l.LineTerminator = "\r\n"
+	l.Info("first")
+	l.Info("already terminated\n")
+	l.Warningf("multi\nline")
+	l.Error("with a stacktrace")
+
+	var out = buf.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Errorf("output does not end with CRLF: %q", out)
+	}
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if line != "" && !strings.HasSuffix(line, "\r\n") {
+			t.Errorf("line does not end with CRLF: %q", line)
+		}
+	}
+	if strings.Contains(out, "\r\r") || strings.Contains(out, "terminated\r\n\r\n") {
+		t.Errorf("line terminator is doubled: %q", out)
+	}
+
+	other.Info("second")
+	if strings.Contains(otherBuf.String(), "\r") {
+		t.Errorf("line terminator of another logger is used: %q", otherBuf.String())
+	}
+}
+
+func TestForSpan(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var span = l.ForSpan("trace-1", "span-2")
+	span.Info("in span")
+	span.Warningf("also in %s\n", "span")
+	l.Info("parent")
+
+	var lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrote %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines[:2] {
+		if !strings.Contains(line, "trace_id=trace-1") || !strings.Contains(line, "span_id=span-2") {
+			t.Errorf("span line is missing the IDs: %q", line)
+		}
+	}
+	if strings.Contains(lines[2], "trace_id") || strings.Contains(lines[2], "span_id") {
+		t.Errorf("parent line has the IDs of the span: %q", lines[2])
+	}
+}
+
+func TestNilWriterUsesFallbackWriter(t *testing.T) {
+	restoreGlobals(t)
+	var buf = &bytes.Buffer{}
+	FallbackWriter = buf
+	var l = NewLogger(DEBUG, nil)
+	l.Info("to the fallback")
+	(&Logger{Loglevel: DEBUG}).Info("zero value too")
+	if !strings.Contains(buf.String(), "to the fallback") || !strings.Contains(buf.String(), "zero value too") {
+		t.Errorf("lines were not written to the FallbackWriter:\n%s", buf.String())
+	}
+}
+
+func TestLogAt(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var past = time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	l.LogAt(past, INFO, "replayed")
+	if want := "2001-02-03 04:05:06 [INFO] replayed\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.Formatter = &JSONFormatter{}
+	l.LogAt(past, INFO, "replayed")
+	var entry LogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Time.Equal(past) {
+		t.Errorf("formatted time %s, want %s", entry.Time, past)
+	}
+}
+
+func TestRequireFieldsStrict(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	l.RequireFields("request_id", "service")
+	if !l.StrictFields {
+		t.Fatal("StrictFields is not enabled under go test")
+	}
+	l.WithFields(NewFields("request_id", 1, "service", "api")).Info("complete")
+
+	defer func() {
+		var r = recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), `"service"`) {
+			t.Errorf("expected a panic about the missing field, got %v", r)
+		}
+	}()
+	l.WithFields(NewFields("request_id", 1)).Info("incomplete")
+}
+
+func TestRequireFieldsWarnsOnce(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.RequireFields("request_id")
+	l.StrictFields = false
+	l.Info("first")
+	l.Info("second")
+	if got := strings.Count(buf.String(), `required field "request_id" is missing`); got != 1 {
+		t.Errorf("warned %d times, want once:\n%s", got, buf.String())
+	}
+}
+
+func TestOnEntry(t *testing.T) {
+	var l, _ = newTestLogger(t, INFO)
+	var entries []*LogEntry
+	l.OnEntry = func(entry *LogEntry) {
+		entries = append(entries, entry)
+	}
+	var child = l.WithFields(NewFields("endpoint", "/users", "status", 200))
+	child.Debug("not emitted")
+	child.Info("request")
+	l.Mute()
+	child.Info("muted")
+
+	if len(entries) != 1 {
+		t.Fatalf("hook was called %d times, want once", len(entries))
+	}
+	var e = entries[0]
+	if e.Level != INFO || e.Message != "request" || e.Fields.String() != "endpoint=/users status=200" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestAuditIgnoresLevel(t *testing.T) {
+	var l, buf = newTestLogger(t, CRITICAL)
+	var audit = &bytes.Buffer{}
+	l.AuditWriter = audit
+	l.Mute()
+
+	l.Error(errors.New("suppressed"))
+	l.Audit("login", NewFields("user", "alice"))
+	l.Audit("permission_change", NewFields("user", "alice", "role", "admin"))
+
+	if buf.Len() != 0 {
+		t.Errorf("audit events were written to the main writer:\n%s", buf.String())
+	}
+	// The header of the JSONFormatter, followed by the events.
+	var lines = strings.Split(strings.TrimSuffix(audit.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], `{"_meta":`) {
+		t.Fatalf("want a header and 2 audit events:\n%s", audit.String())
+	}
+	var entry struct {
+		Message string
+		Fields  map[string]string
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Message != "permission_change" || entry.Fields["role"] != "admin" {
+		t.Errorf("unexpected audit event %+v", entry)
+	}
+}
+
+func TestTimed(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Clock = nil
+	var entry *LogEntry
+	l.OnEntry = func(e *LogEntry) { entry = e }
+
+	var done = l.Timed(INFO, "handler")
+	time.Sleep(20 * time.Millisecond)
+	done()
+
+	if entry == nil {
+		t.Fatal("nothing was logged")
+	}
+	var label, _ = entry.Fields.Get("label")
+	var duration, _ = entry.Fields.Get("duration")
+	if label != "handler" {
+		t.Errorf("label is %v, want handler", label)
+	}
+	if d, ok := duration.(time.Duration); !ok || d < 20*time.Millisecond {
+		t.Errorf("duration is %v, want at least 20ms", duration)
+	}
+	if !strings.Contains(buf.String(), "[INFO] handler took ") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestInfoKV(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.InfoKV("synchronizing", "op", "sync", "user", 42, "attempt", 3)
+	if want := "2024-01-02 03:04:05 [INFO] synchronizing  op=sync user=42 attempt=3\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.InfoKV("synchronizing", "op", "sync", "user")
+	if want := "2024-01-02 03:04:05 [INFO] synchronizing  op=sync user=<missing>\n"; buf.String() != want {
+		t.Errorf("odd pairs: got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.InfoKV("synchronizing", "op", "sync")
+	if !strings.Contains(buf.String(), Colorize("op=sync", DimGrey)) {
+		t.Errorf("context segment is not dimmed: %q", buf.String())
+	}
+}
+
+func TestPrintLegend(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.PrintLegend()
+	if want := "Legend: TEST DEBUG INFO WARNING ERROR CRITICAL\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.PrintLegend()
+	for _, level := range []Loglevel{TEST, DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+		if !strings.Contains(buf.String(), Colorize(level.String(), getLogLevelColor(level))) {
+			t.Errorf("%s is not written in its color: %q", level, buf.String())
+		}
+	}
+}
+
+func TestUptime(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Uptime = true
+	var uptimes []time.Duration
+	l.OnEntry = func(e *LogEntry) {
+		var uptime, _ = e.Fields.Get("uptime")
+		uptimes = append(uptimes, uptime.(time.Duration))
+	}
+	l.Info("first")
+	time.Sleep(time.Millisecond)
+	l.Info("second")
+
+	if len(uptimes) != 2 || uptimes[1] <= uptimes[0] {
+		t.Errorf("uptime does not increase: %v", uptimes)
+	}
+	// The uptime is added to the timestamp, not written instead of it.
+	if !strings.HasPrefix(buf.String(), "2024-01-02 03:04:05 [INFO] first uptime=") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+// sprintfPrefix is the prefix as it was built with fmt.Sprintf, before generatePrefix wrote into a pooled buffer.
+func sprintfPrefix(colorized bool, prefix string, level Loglevel, t time.Time) string {
+	var msg = fmt.Sprintf("%s [%s%s] ", RenderOptions{}.formatTime(t), prefix, level.String())
+	if colorized {
+		msg = Colorize(msg, getLogLevelColor(level))
+	}
+	return msg
+}
+
+func TestGeneratePrefixOutput(t *testing.T) {
+	restoreGlobals(t)
+	for _, level := range []Loglevel{TEST, DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+		for _, prefix := range []string{"", "app "} {
+			for _, colorized := range []bool{false, true} {
+				var color Color
+				if colorized {
+					color = getLogLevelColor(level)
+				}
+				var got = generatePrefix(color, prefix, level, testTime, RenderOptions{})
+				if want := sprintfPrefix(colorized, prefix, level, testTime); got != want {
+					t.Errorf("%s, prefix %q, colorized %t: got %q, want %q", level, prefix, colorized, got, want)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkLog(b *testing.B) {
+	var l, _ = newTestLogger(b, INFO, "app ")
+	l.File = io.Discard
+	l.Colorized = true
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+}
+
+func BenchmarkGeneratePrefix(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		generatePrefix(Blue, "app ", INFO, testTime, RenderOptions{})
+	}
+}
+
+// BenchmarkSprintfPrefix is the baseline for BenchmarkGeneratePrefix.
+func BenchmarkSprintfPrefix(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sprintfPrefix(true, "app ", INFO, testTime)
+	}
+}
+
+func TestMaxPrefixWidth(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG, "very-long-service-name ")
+	l.MaxPrefixWidth = 10
+	l.Info("message")
+	// The separator is kept, so the name is cut to 9 columns, including the ellipsis.
+	if want := "2024-01-02 03:04:05 [very-lon… INFO] message\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.Info("message")
+	var want = Colorize("2024-01-02 03:04:05 [very-lon… INFO] ", getLogLevelColor(INFO))
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("truncation breaks the colors: got %q, want prefix %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.MaxPrefixWidth = 0
+	l.Colorized = false
+	l.Info("message")
+	if !strings.Contains(buf.String(), "[very-long-service-name INFO]") {
+		t.Errorf("prefix was cut without a MaxPrefixWidth: %q", buf.String())
+	}
+}
+
+func TestColorFuncByField(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Colorized = true
+	l.ColorFunc = func(entry *LogEntry) Color {
+		if tenant, _ := entry.Fields.Get("tenant"); tenant == "acme" {
+			return Purple
+		}
+		return getLogLevelColor(entry.Level)
+	}
+	l.WithFields(NewFields("tenant", "acme")).Info("acme line")
+	l.WithFields(NewFields("tenant", "other")).Info("other line")
+
+	var lines = strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], Purple) {
+		t.Errorf("line is not colored by the tenant: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], getLogLevelColor(INFO)) {
+		t.Errorf("line is not colored by the loglevel: %q", lines[1])
+	}
+}
+
+func TestLevelOffset(t *testing.T) {
+	var l, buf = newTestLogger(t, INFO)
+	l.LevelOffset = 1
+	l.Info("quiet info")
+	l.Warning("warning")
+	if got := buf.String(); strings.Contains(got, "quiet info") || !strings.Contains(got, "[WARNING] warning") {
+		t.Errorf("offset 1 on INFO: unexpected output %q", got)
+	}
+	if l.Loglevel != INFO {
+		t.Errorf("Loglevel was changed to %s", l.Loglevel)
+	}
+
+	buf.Reset()
+	l.LevelOffset = -1
+	l.Debug("verbose debug")
+	l.Test("test")
+	if got := buf.String(); !strings.Contains(got, "[DEBUG] verbose debug") || strings.Contains(got, "test") {
+		t.Errorf("offset -1 on INFO: unexpected output %q", got)
+	}
+}
+
+// The size field of a line written with LogEntrySize.
+var sizeField = regexp.MustCompile(`size=(\d+)`)
+
+func TestLogEntrySize(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.LogEntrySize = true
+	var check = func(name string, written string) {
+		t.Helper()
+		var m = sizeField.FindStringSubmatch(written)
+		if m == nil {
+			t.Fatalf("%s: no size field in %q", name, written)
+		}
+		if size, _ := strconv.Atoi(m[1]); size != len(DeColorize(written)) {
+			t.Errorf("%s: size field is %d, wrote %d bytes: %q", name, size, len(DeColorize(written)), written)
+		}
+	}
+
+	l.Info("known message")
+	check("plain", buf.String())
+
+	buf.Reset()
+	l.Colorized = true
+	l.WithFields(NewFields("user", 42)).Info("known message")
+	check("colorized", buf.String())
+
+	buf.Reset()
+	l.Colorized = false
+	l.Error(errors.New("with a stacktrace"))
+	check("stacktrace", buf.String())
+}
+
+func TestWithColor(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG, "app ")
+	var write = func(l *Logger) string {
+		buf.Reset()
+		l.WithFields(NewFields("user", 42)).Info("message")
+		l.Warningf("warning %d\n", 1)
+		l.Error(errors.New("boom"))
+		return buf.String()
+	}
+	// Written from the same line, so the stacktraces are the same.
+	var outputs = map[bool]string{}
+	for _, colorized := range []bool{true, false} {
+		outputs[colorized] = write(l.WithColor(colorized))
+	}
+	var colored, plain = outputs[true], outputs[false]
+
+	if strings.Contains(plain, "\x1b[") {
+		t.Errorf("uncolored output contains color codes:\n%q", plain)
+	}
+	if !strings.Contains(colored, "\x1b[") {
+		t.Errorf("colored output contains no color codes:\n%q", colored)
+	}
+	if DeColorize(colored) != plain {
+		t.Errorf("uncolored output differs from the colored output without colors:\n%s\nwant:\n%s", plain, DeColorize(colored))
+	}
+	if l.Colorized {
+		t.Error("WithColor changed the logger itself")
+	}
+}
@@ -0,0 +1,50 @@
+// Package loggertest provides helpers to check what was logged in tests.
+package loggertest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/Nigel2392/request-logger"
+)
+
+// ExpectNoLogsAbove installs a logger as the default logger, which captures the entries at the level and above.
+//
+// The returned function puts the previous default logger back, and fails the test
+// with the captured entries if any were logged. It is meant to be deferred:
+//
+//	defer loggertest.ExpectNoLogsAbove(t, logger.ERROR)()
+//
+// Only code which logs through the default logger is checked, see logger.SetDefault.
+func ExpectNoLogsAbove(t testing.TB, level logger.Loglevel) (restore func()) {
+	t.Helper()
+	var mutex = &sync.Mutex{}
+	var captured []*logger.LogEntry
+	var l = logger.NewLogger(level, io.Discard)
+	l.OnEntry = func(entry *logger.LogEntry) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		captured = append(captured, entry)
+	}
+
+	var previous = logger.Default()
+	logger.SetDefault(l)
+	return func() {
+		t.Helper()
+		logger.SetDefault(previous)
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(captured) == 0 {
+			return
+		}
+		var b = &strings.Builder{}
+		fmt.Fprintf(b, "expected no logs at %s or above, got %d:", level, len(captured))
+		for _, entry := range captured {
+			fmt.Fprintf(b, "\n\t[%s] %s", entry.Level, entry.Message)
+		}
+		t.Error(b.String())
+	}
+}
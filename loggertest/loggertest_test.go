@@ -0,0 +1,47 @@
+package loggertest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	logger "github.com/Nigel2392/request-logger"
+)
+
+// fakeT records the errors of a test, instead of failing it.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Error(args ...any) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func TestExpectNoLogsAbove(t *testing.T) {
+	var previous = logger.Default()
+
+	var ft = &fakeT{TB: t}
+	var restore = ExpectNoLogsAbove(ft, logger.ERROR)
+	logger.Warning("only a warning")
+	logger.Error(errors.New("boom"))
+	restore()
+	if len(ft.errors) != 1 || !strings.Contains(ft.errors[0], "[ERROR] boom") || strings.Contains(ft.errors[0], "warning") {
+		t.Errorf("unexpected errors %q, want the error entry reported", ft.errors)
+	}
+
+	ft = &fakeT{TB: t}
+	restore = ExpectNoLogsAbove(ft, logger.ERROR)
+	logger.Warning("only a warning")
+	restore()
+	if len(ft.errors) != 0 {
+		t.Errorf("unexpected errors %q, want none", ft.errors)
+	}
+
+	if logger.Default() != previous {
+		t.Error("the previous default logger was not restored")
+	}
+}
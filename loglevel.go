@@ -1,5 +1,14 @@
 package logger
 
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 type Loglevel int
 
 const (
@@ -11,6 +20,58 @@ const (
 	TEST
 )
 
+// TestLevelEnabled reports whether messages with the TEST loglevel are written.
+//
+// By default this is only the case when running under "go test",
+// replace it to write or suppress test messages regardless.
+var TestLevelEnabled = isTestBinary
+
+// isTestBinary reports whether the program is a test binary built by "go test".
+func isTestBinary() bool {
+	return flag.Lookup("test.v") != nil
+}
+
+// Check if a message with the given level should be written at the loglevel.
+func allowLevel(loglevel, level Loglevel) bool {
+	if level == TEST && !TestLevelEnabled() {
+		return false
+	}
+	return level.AtLeast(loglevel)
+}
+
+// The severity of a built-in loglevel is its value times the severityStep,
+// which leaves room for registered levels in between.
+const severityStep = 10
+
+// Severity returns the position of the loglevel in the order of severity, a lower severity is more severe.
+//
+// The built-in levels have a severity of 10 for CRITICAL up to 60 for TEST,
+// registered levels have the severity they were registered with.
+func (l Loglevel) Severity() int {
+	if l >= CRITICAL && l <= TEST {
+		return int(l) * severityStep
+	}
+	if custom, ok := customLevel(l); ok {
+		return custom.severity
+	}
+	return int(l) * severityStep
+}
+
+// MoreSevereThan reports whether the loglevel is more severe than the other, such as ERROR than WARNING.
+func (l Loglevel) MoreSevereThan(other Loglevel) bool {
+	return l.Severity() < other.Severity()
+}
+
+// LessSevereThan reports whether the loglevel is less severe than the other, such as INFO than WARNING.
+func (l Loglevel) LessSevereThan(other Loglevel) bool {
+	return l.Severity() > other.Severity()
+}
+
+// AtLeast reports whether the loglevel is at least as severe as the other.
+func (l Loglevel) AtLeast(other Loglevel) bool {
+	return l.Severity() <= other.Severity()
+}
+
 func (l Loglevel) String() string {
 	switch l {
 	case CRITICAL:
@@ -25,9 +86,149 @@ func (l Loglevel) String() string {
 		return "DEBUG"
 	case TEST:
 		return "TEST"
-	default:
-		return "UNKNOWN"
 	}
+	if custom, ok := customLevel(l); ok {
+		return custom.name
+	}
+	return "UNKNOWN"
+}
+
+// ParseLoglevel returns the loglevel with the name, such as "DEBUG" or "warning", ignoring case.
+//
+// The names of levels registered with RegisterLevel are accepted too, and so is the number of a built-in or registered loglevel.
+// An error naming the valid levels is returned for unknown names and numbers.
+func ParseLoglevel(s string) (Loglevel, error) {
+	var name = strings.TrimSpace(s)
+	var all = levels()
+	for _, level := range all {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, level := range all {
+			if level == Loglevel(n) {
+				return level, nil
+			}
+		}
+	}
+	var names = make([]string, len(all))
+	for i, level := range all {
+		names[i] = level.String()
+	}
+	return 0, fmt.Errorf("logger: unknown loglevel %q, valid levels are %s", s, strings.Join(names, ", "))
+}
+
+// MarshalText returns the name of the loglevel, or its number if it has no name.
+func (l Loglevel) MarshalText() ([]byte, error) {
+	var name = l.String()
+	if name == "UNKNOWN" {
+		name = strconv.Itoa(int(l))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText sets the loglevel from its name, see ParseLoglevel.
+func (l *Loglevel) UnmarshalText(text []byte) error {
+	var level, err = ParseLoglevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// A loglevel registered with RegisterLevel.
+type registeredLevel struct {
+	name     string
+	color    Color
+	severity int
+}
+
+var (
+	customLevelsMutex = &sync.RWMutex{}
+	customLevels      = map[Loglevel]registeredLevel{}
+)
+
+// RegisterLevel registers a custom loglevel with a name and color.
+//
+// Like the built-in levels, a lower value is more severe: the value decides which loglevels write the level.
+// For example, a TRACE level with the value TEST+1 is only written by loggers with a loglevel of TRACE.
+// The name is used when writing and parsing the level.
+//
+// To register a level in between the built-in levels, use RegisterLevelWithSeverity.
+//
+// An error is returned if the value or name is already used by a built-in or registered level, or if the value is 0.
+func RegisterLevel(name string, value Loglevel, color Color) (Loglevel, error) {
+	return RegisterLevelWithSeverity(name, value, int(value)*severityStep, color)
+}
+
+// RegisterLevelWithSeverity registers a custom loglevel with a name, color and severity, see Loglevel.Severity.
+//
+// The severity decides which loglevels write the level, and can be in between the severities of the built-in levels:
+//
+//	var NOTICE, _ = logger.RegisterLevelWithSeverity("NOTICE", 10, logger.INFO.Severity()-5, logger.Cyan)
+//
+// An error is returned if the value, name or severity is already used by a built-in or registered level,
+// or if the value is 0.
+func RegisterLevelWithSeverity(name string, value Loglevel, severity int, color Color) (Loglevel, error) {
+	if name == "" {
+		return 0, fmt.Errorf("logger: cannot register a loglevel without a name")
+	}
+	if value == 0 {
+		return 0, fmt.Errorf("logger: cannot register loglevel %s, value 0 is not a valid loglevel", name)
+	}
+	if value >= CRITICAL && value <= TEST {
+		return 0, fmt.Errorf("logger: cannot register loglevel %s, value %d is used by %s", name, value, value)
+	}
+	customLevelsMutex.Lock()
+	defer customLevelsMutex.Unlock()
+	if existing, ok := customLevels[value]; ok {
+		return 0, fmt.Errorf("logger: cannot register loglevel %s, value %d is used by %s", name, value, existing.name)
+	}
+	for level := CRITICAL; level <= TEST; level++ {
+		if strings.EqualFold(level.String(), name) {
+			return 0, fmt.Errorf("logger: cannot register loglevel %s, the name is already used", name)
+		}
+		if int(level)*severityStep == severity {
+			return 0, fmt.Errorf("logger: cannot register loglevel %s, severity %d is used by %s", name, severity, level)
+		}
+	}
+	for _, existing := range customLevels {
+		if strings.EqualFold(existing.name, name) {
+			return 0, fmt.Errorf("logger: cannot register loglevel %s, the name is already used", name)
+		}
+		if existing.severity == severity {
+			return 0, fmt.Errorf("logger: cannot register loglevel %s, severity %d is used by %s", name, severity, existing.name)
+		}
+	}
+	customLevels[value] = registeredLevel{name: name, color: color, severity: severity}
+	return value, nil
+}
+
+// customLevel returns the registered level of the value.
+func customLevel(l Loglevel) (registeredLevel, bool) {
+	customLevelsMutex.RLock()
+	defer customLevelsMutex.RUnlock()
+	var level, ok = customLevels[l]
+	return level, ok
+}
+
+// levels returns the built-in and registered loglevels, from most to least severe.
+func levels() []Loglevel {
+	var all = []Loglevel{CRITICAL, ERROR, WARNING, INFO, DEBUG, TEST}
+	customLevelsMutex.RLock()
+	defer customLevelsMutex.RUnlock()
+	var severity = make(map[Loglevel]int, len(all)+len(customLevels))
+	for _, level := range all {
+		severity[level] = int(level) * severityStep
+	}
+	for level, custom := range customLevels {
+		all = append(all, level)
+		severity[level] = custom.severity
+	}
+	sort.Slice(all, func(i, j int) bool { return severity[all[i]] < severity[all[j]] })
+	return all
 }
 
 // getLogLevelColor returns the color for a loglevel.
@@ -46,5 +247,8 @@ func getLogLevelColor(level Loglevel) string {
 	case TEST:
 		return ColorLevelTest
 	}
+	if custom, ok := customLevel(level); ok {
+		return custom.color
+	}
 	return ColorLevelInfo
 }
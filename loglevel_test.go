@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// registerTestLevel registers a level with the severity for the duration of the test.
+func registerTestLevel(t *testing.T, name string, value Loglevel, severity int) Loglevel {
+	t.Helper()
+	var level, err = RegisterLevelWithSeverity(name, value, severity, Cyan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		customLevelsMutex.Lock()
+		delete(customLevels, level)
+		customLevelsMutex.Unlock()
+	})
+	return level
+}
+
+func TestRegisterLevelBetweenBuiltins(t *testing.T) {
+	var notice = registerTestLevel(t, "NOTICE", 10, INFO.Severity()-5)
+
+	if !notice.MoreSevereThan(INFO) || !notice.LessSevereThan(WARNING) {
+		t.Errorf("NOTICE is not between INFO and WARNING")
+	}
+	var all = levels()
+	var names = make([]string, len(all))
+	for i, level := range all {
+		names[i] = level.String()
+	}
+	if got := strings.Join(names, ","); got != "CRITICAL,ERROR,WARNING,NOTICE,INFO,DEBUG,TEST" {
+		t.Errorf("levels() = %s", got)
+	}
+
+	var l, buf = newTestLogger(t, notice)
+	l.Info("dropped")
+	l.LogAt(testTime, notice, "written")
+	l.Warning("written")
+	if strings.Contains(buf.String(), "dropped") || strings.Count(buf.String(), "written") != 2 {
+		t.Errorf("unexpected output at loglevel NOTICE:\n%s", buf.String())
+	}
+}
+
+func TestRegisterLevelRejects(t *testing.T) {
+	registerTestLevel(t, "NOTICE", 10, 35)
+	var tests = map[string]struct {
+		name     string
+		value    Loglevel
+		severity int
+	}{
+		"zero value":        {"ZERO", 0, 70},
+		"built-in value":    {"OTHER", INFO, 70},
+		"registered value":  {"OTHER", 10, 70},
+		"built-in name":     {"info", 11, 70},
+		"registered name":   {"notice", 11, 70},
+		"built-in severity": {"OTHER", 11, 40},
+		"used severity":     {"OTHER", 11, 35},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := RegisterLevelWithSeverity(tt.name, tt.value, tt.severity, Cyan); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseLoglevel(t *testing.T) {
+	var notice = registerTestLevel(t, "NOTICE", 10, 35)
+	var tests = map[string]Loglevel{
+		"warning": WARNING,
+		" INFO ":  INFO,
+		"Notice":  notice,
+		"5":       DEBUG,
+		"10":      notice,
+	}
+	for s, want := range tests {
+		if got, err := ParseLoglevel(s); err != nil || got != want {
+			t.Errorf("ParseLoglevel(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "0", "42", "-1", "verbose"} {
+		if level, err := ParseLoglevel(s); err == nil {
+			t.Errorf("ParseLoglevel(%q) = %v, want an error", s, level)
+		}
+	}
+}
+
+func TestTestLevel(t *testing.T) {
+	var l, buf = newTestLogger(t, TEST)
+	l.Test("under go test")
+	if !strings.Contains(buf.String(), "[TEST] under go test") {
+		t.Errorf("TEST message was not written under go test:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	TestLevelEnabled = func() bool { return false }
+	l.Test("in production")
+	l.Debug("debug")
+	if strings.Contains(buf.String(), "in production") || !strings.Contains(buf.String(), "debug") {
+		t.Errorf("TEST messages are not suppressed outside of tests:\n%s", buf.String())
+	}
+}
+
+func TestLevelOrdering(t *testing.T) {
+	// From the most to the least severe.
+	var levels = []Loglevel{CRITICAL, ERROR, WARNING, INFO, DEBUG, TEST}
+	for i, a := range levels {
+		for j, b := range levels {
+			if got := a.MoreSevereThan(b); got != (i < j) {
+				t.Errorf("%s.MoreSevereThan(%s) = %t", a, b, got)
+			}
+			if got := a.LessSevereThan(b); got != (i > j) {
+				t.Errorf("%s.LessSevereThan(%s) = %t", a, b, got)
+			}
+			if got := a.AtLeast(b); got != (i <= j) {
+				t.Errorf("%s.AtLeast(%s) = %t", a, b, got)
+			}
+		}
+	}
+}
+
+func TestLoglevelJSONRoundTrip(t *testing.T) {
+	type config struct {
+		Level Loglevel `json:"level"`
+	}
+	for _, level := range []Loglevel{TEST, DEBUG, INFO, WARNING, ERROR, CRITICAL} {
+		var data, err = json.Marshal(config{level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"level":"` + level.String() + `"}`; string(data) != want {
+			t.Errorf("marshalled %s to %s, want %s", level, data, want)
+		}
+		var c config
+		if err := json.Unmarshal(data, &c); err != nil || c.Level != level {
+			t.Errorf("%s round-trips to %s, %v", level, c.Level, err)
+		}
+	}
+
+	var c config
+	var err = json.Unmarshal([]byte(`{"level":"verbose"}`), &c)
+	if err == nil || !strings.Contains(err.Error(), "WARNING") {
+		t.Errorf("unknown level: got %v, want an error naming the valid levels", err)
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import "os"
+
+// Exit is called by Must and MustLogger to exit the program when an error occurs.
+//
+// It can be replaced to keep the program running, for example in tests.
+var Exit = os.Exit
+
+// Must returns v if err is nil.
+//
+// Otherwise, a critical line with the error is written to stderr and the program exits with status 1.
+// This keeps setup code short, for example:
+//
+//	var file = logger.Must(logger.NewLogFile("app.log"))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		NewLogger(CRITICAL, os.Stderr).Critical(err)
+		Exit(1)
+	}
+	return v
+}
+
+// MustLogger returns l if err is nil, see Must.
+func MustLogger(l *Logger, err error) *Logger {
+	return Must(l, err)
+}
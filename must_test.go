@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	restoreGlobals(t)
+	var code = -1
+	Exit = func(c int) { code = c }
+
+	if got := Must(42, nil); got != 42 || code != -1 {
+		t.Errorf("Must returned %d and exited with %d, want 42 without exiting", got, code)
+	}
+
+	// Must writes the error to stderr.
+	var stderr = os.Stderr
+	var f, err = os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	os.Stderr = f
+	Must(0, errors.New("setup failed"))
+	os.Stderr = stderr
+
+	if code != 1 {
+		t.Errorf("exited with %d, want 1", code)
+	}
+	var out, _ = os.ReadFile(f.Name())
+	if !strings.Contains(string(out), "CRITICAL") || !strings.Contains(string(out), "setup failed") {
+		t.Errorf("no critical line was written to stderr:\n%s", out)
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import "sync"
+
+// muteTracker counts the mutes of a logger.
+type muteTracker struct {
+	mutex *sync.Mutex
+	count int
+}
+
+func newMuteTracker() *muteTracker {
+	return &muteTracker{mutex: &sync.Mutex{}}
+}
+
+// muted reports whether the logger is muted.
+func (m *muteTracker) muted() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.count > 0
+}
+
+// Mute suppresses all output of the logger until the returned restore function or Unmute is called.
+//
+// This only affects this logger and the loggers derived from it, not other loggers sharing its writer.
+// Mutes nest, the logger writes again once every mute is restored.
+// Errors are still written if MuteKeepErrors is set.
+//
+//	defer l.Mute()()
+func (l *Logger) Mute() (restore func()) {
+	var m = l.ownScope().mutes
+	m.mutex.Lock()
+	m.count++
+	m.mutex.Unlock()
+	var once = &sync.Once{}
+	return func() {
+		once.Do(func() {
+			m.mutex.Lock()
+			if m.count > 0 {
+				m.count--
+			}
+			m.mutex.Unlock()
+		})
+	}
+}
+
+// Unmute restores all mutes of the logger, so it writes again.
+//
+// A logger derived from a muted logger stays muted until that logger is unmuted.
+func (l *Logger) Unmute() {
+	var m = l.ownScope().mutes
+	m.mutex.Lock()
+	m.count = 0
+	m.mutex.Unlock()
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMuteDerivedLogger(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var child = l.WithFields(NewFields("request", 1))
+
+	var restore = child.Mute()
+	child.Info("child muted")
+	l.Info("parent written")
+	restore()
+	child.Info("child written")
+
+	var out = buf.String()
+	if strings.Contains(out, "child muted") {
+		t.Errorf("muted child wrote a line:\n%s", out)
+	}
+	if !strings.Contains(out, "parent written") || !strings.Contains(out, "child written") {
+		t.Errorf("missing lines:\n%s", out)
+	}
+}
+
+func TestMuteParentMutesDerived(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var child = l.Sub(WithField("module", "auth"))
+
+	l.Mute()
+	child.Info("child muted")
+	child.Unmute()
+	child.Info("still muted")
+	l.Unmute()
+	child.Info("written")
+
+	var out = buf.String()
+	if strings.Contains(out, "muted") {
+		t.Errorf("child of a muted logger wrote a line:\n%s", out)
+	}
+	if !strings.Contains(out, "written") {
+		t.Errorf("missing line after unmute:\n%s", out)
+	}
+}
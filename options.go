@@ -0,0 +1,38 @@
+package logger
+
+// An Option changes a setting of a logger, see Logger.Sub.
+type Option func(*Logger)
+
+// WithPrefix sets the prefix of the logger.
+func WithPrefix(prefix string) Option {
+	return func(l *Logger) {
+		l.prefix = prefix
+	}
+}
+
+// WithLevel sets the loglevel of the logger.
+func WithLevel(level Loglevel) Option {
+	return func(l *Logger) {
+		l.Loglevel = level
+	}
+}
+
+// WithField adds a field to every line of the logger.
+func WithField(key string, value any) Option {
+	return func(l *Logger) {
+		l.fields = l.fields.With(key, value)
+	}
+}
+
+// Sub returns a copy of the logger with the options applied.
+//
+// The logger itself is not changed:
+//
+//	var auth = l.Sub(logger.WithPrefix("auth"), logger.WithLevel(logger.DEBUG), logger.WithField("module", "login"))
+func (l *Logger) Sub(opts ...Option) *Logger {
+	var clone = l.derive()
+	for _, opt := range opts {
+		opt(clone)
+	}
+	return clone
+}
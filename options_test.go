@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestSub(t *testing.T) {
+	var l, buf = newTestLogger(t, INFO)
+	var sub = l.Sub(WithPrefix("auth "), WithLevel(DEBUG), WithField("mod", "login"))
+
+	sub.Debug("from sub")
+	l.Debug("from parent")
+	l.Info("from parent")
+
+	var want = "2024-01-02 03:04:05 [auth DEBUG] from sub mod=login\n" +
+		"2024-01-02 03:04:05 [INFO] from parent\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if l.prefix != "" || l.Loglevel != INFO || len(l.fields) != 0 {
+		t.Errorf("parent was changed: prefix %q, level %s, fields %v", l.prefix, l.Loglevel, l.fields)
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The part of the MaxTotalBytes which lines below loglevel error may use,
+// the rest is kept for errors.
+const errorReserve = 0.1
+
+// outputCap counts the bytes written by a logger, to enforce the MaxTotalBytes.
+type outputCap struct {
+	mutex *sync.Mutex
+
+	// The amount of bytes written, and whether the notice about the cap was written.
+	written  int64
+	notified bool
+}
+
+func newOutputCap() *outputCap {
+	return &outputCap{mutex: &sync.Mutex{}}
+}
+
+// allow counts a line of n bytes, and reports whether it may be written.
+//
+// If not, notify reports whether the notice about the cap should be written.
+func (c *outputCap) allow(n int, level Loglevel, max int64) (allow, notify bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var limit = max
+	if level.LessSevereThan(ERROR) {
+		limit = max - int64(float64(max)*errorReserve)
+	}
+	if c.written+int64(n) <= limit {
+		c.written += int64(n)
+		return true, false
+	}
+	notify = !c.notified
+	c.notified = true
+	return false, notify
+}
+
+// capOutput reports whether a line of n bytes may be written under the MaxTotalBytes,
+// and writes the notice about the cap when the first line is dropped.
+func (l *Logger) capOutput(t time.Time, level Loglevel, n int) bool {
+	var allow, notify = l.shared().output.allow(n, level, l.MaxTotalBytes)
+	if notify {
+		l.put(WARNING, l.render(t, WARNING, fmt.Sprintf(
+			"log output capped at %d bytes, further lines are dropped\n", l.MaxTotalBytes,
+		)), nil, false)
+	}
+	return allow
+}
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxTotalBytesAllWritePaths(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.MaxTotalBytes = 64
+	l.Info(strings.Repeat("x", 40))
+	l.Info("dropped")
+
+	var capped = buf.Len()
+	if !strings.Contains(buf.String(), "log output capped at 64 bytes") {
+		t.Fatalf("missing the notice about the cap:\n%s", buf.String())
+	}
+
+	l.Banner("app", "v1.0.0", "abcdef")
+	l.PrintLegend()
+	l.Progress("upload", 1)
+	var g = l.Group("group")
+	g.Info("in group")
+	g.End()
+
+	if buf.Len() != capped {
+		t.Errorf("lines were written past the cap:\n%s", buf.String()[capped:])
+	}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+// The time of a line, either a timestamp or the relative time written when RenderOptions.RelativeTime is set.
+const timePattern = `(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}|\+-?\d+\.\d{3}s)`
+
+var (
+	// 2006-01-02 15:04:05 [ prefixLEVEL ] - message
+	// +0.123s [prefixLEVEL] message
+	singleLineRex = regexp.MustCompile(`^` + timePattern + ` \[ ?([^\]]*?) ?\](?: -)? ?(.*)$`)
+	// [ prefixLEVEL ] - 2006-01-02 15:04:05
+	multiLineRex = regexp.MustCompile(`^\[ ?([^\]]*?) ?\] - ` + timePattern + `$`)
+)
+
+// ParseLine parses a line written by the logger back into a log entry.
+//
+// Both the single-line and the multi-line header format are understood,
+// colors are removed before parsing.
+// A relative time, written when RenderOptions.RelativeTime is set, is parsed as the time since the process started.
+//
+// The entry of a multi-line header has no message, the stacktrace is never parsed.
+func ParseLine(line string) (*LogEntry, error) {
+	line = strings.TrimRight(DeColorize(line), "\r\n")
+
+	var timeStr, levelStr, message string
+	if m := singleLineRex.FindStringSubmatch(line); m != nil {
+		timeStr, levelStr, message = m[1], m[2], m[3]
+	} else if m := multiLineRex.FindStringSubmatch(line); m != nil {
+		levelStr, timeStr = m[1], m[2]
+	} else {
+		return nil, fmt.Errorf("logger: could not parse line %q", line)
+	}
+
+	var t, err = parseTime(timeStr)
+	if err != nil {
+		return nil, fmt.Errorf("logger: could not parse time of line %q: %w", line, err)
+	}
+
+	var level = levelFromSuffix(levelStr)
+	if level == 0 {
+		return nil, fmt.Errorf("logger: unknown loglevel in line %q", line)
+	}
+
+	return &LogEntry{
+		Time:    t,
+		Level:   level,
+		Message: message,
+	}, nil
+}
+
+// parseTime parses the time of a line, a timestamp or a relative time such as "+1.500s".
+func parseTime(s string) (time.Time, error) {
+	if !strings.HasPrefix(s, "+") {
+		return time.ParseInLocation(timeFormat, s, time.Local)
+	}
+	var seconds, err = strconv.ParseFloat(strings.TrimSuffix(s[1:], "s"), 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return startTime.Add(time.Duration(seconds * float64(time.Second))), nil
+}
+
+// levelFromSuffix returns the loglevel the string ends with.
+//
+// The level is written directly after the prefix, so only the suffix is checked.
+func levelFromSuffix(s string) Loglevel {
+	for _, level := range levels() {
+		if strings.HasSuffix(s, level.String()) {
+			return level
+		}
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	var entry, err = ParseLine("2024-01-02 03:04:05 [app WARNING] disk almost full\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != WARNING || entry.Message != "disk almost full" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local); !entry.Time.Equal(want) {
+		t.Errorf("time is %v, want %v", entry.Time, want)
+	}
+}
+
+func TestParseLineRelativeTime(t *testing.T) {
+	var entry, err = ParseLine("+1.500s [INFO] hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != INFO || entry.Message != "hello" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if got := entry.Time.Sub(startTime); got != 1500*time.Millisecond {
+		t.Errorf("time is %v after the start, want 1.5s", got)
+	}
+
+	if _, err = ParseLine("[ INFO ] - +0.000s"); err != nil {
+		t.Errorf("multi-line header: %v", err)
+	}
+}
+
+func TestLogViewerRelativeTime(t *testing.T) {
+	var rb = NewRingBufferWriter(10)
+	var l, _ = newTestLogger(t, DEBUG)
+	l.RelativeTime = true
+	l.File = rb
+	l.Info("relative line")
+
+	var _, body = viewLogs(rb, "?level=info")
+	if !strings.Contains(body, "relative line") {
+		t.Errorf("line with a relative time was dropped:\n%s", body)
+	}
+}
+
+func TestParseLineRoundTrip(t *testing.T) {
+	restoreGlobals(t)
+	var at = time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	var entries = []*LogEntry{
+		{Time: at, Level: INFO, Message: "hello world"},
+		{Time: at.Add(time.Second), Level: DEBUG, Message: "with [brackets]"},
+		{Time: at.Add(time.Minute), Level: CRITICAL, Message: "first\nsecond"},
+	}
+	for _, colorized := range []bool{false, true} {
+		for _, entry := range entries {
+			var text = entry.AsString("app ", colorized)
+			var line = strings.SplitAfter(text, "\n")[0]
+			var parsed, err = ParseLine(line)
+			if err != nil {
+				t.Errorf("parsing %q: %v", line, err)
+				continue
+			}
+			if !parsed.Time.Equal(entry.Time) || parsed.Level != entry.Level {
+				t.Errorf("parsed %q as %v %s, want %v %s", line, parsed.Time, parsed.Level, entry.Time, entry.Level)
+			}
+			if !strings.Contains(entry.Message, "\n") && parsed.Message != entry.Message {
+				t.Errorf("parsed message %q, want %q", parsed.Message, entry.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// IsTerminal reports whether the writer is a terminal.
+//
+// It can be replaced to control the output of Progress, for example in tests.
+var IsTerminal = func(w io.Writer) bool {
+	var f, ok = w.(*os.File)
+	if !ok {
+		return false
+	}
+	var info, err = f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// The width of the bar written by Progress.
+const progressBarWidth = 20
+
+// progressTracker tracks the progress lines of a logger.
+type progressTracker struct {
+	mutex *sync.Mutex
+
+	// Whether a progress line without a line ending was written to a terminal.
+	active bool
+
+	// The last written fraction per label, when not writing to a terminal.
+	written map[string]float64
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{
+		mutex:   &sync.Mutex{},
+		written: make(map[string]float64),
+	}
+}
+
+// interrupt ends an active progress line with the terminator, so the next line is not written over it.
+func (p *progressTracker) interrupt(w io.Writer, terminator string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.active {
+		io.WriteString(w, terminator)
+		p.active = false
+	}
+}
+
+// setActive sets whether a progress line without a line ending was written.
+func (p *progressTracker) setActive(active bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.active = active
+}
+
+// Progress writes the progress of a task at loglevel info, fraction is the part of the task which is done, from 0 to 1.
+//
+// On a terminal, unless the logger has a Formatter or sinks, the progress line is overwritten in place until the task is done.
+// Otherwise, a line is only written once the progress has increased by the ProgressStep since the last line of the label,
+// and when the task is done.
+func (l *Logger) Progress(label string, fraction float64) {
+	if !l.enabled(INFO) {
+		return
+	}
+	if fraction < 0 {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+
+	var filled = int(fraction * progressBarWidth)
+	var msg = fmt.Sprintf("%s [%s%s] %3.0f%%",
+		label, strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), fraction*100,
+	)
+
+	var t = l.now()
+	if l.Formatter == nil && IsTerminal(l.writer()) && !l.shared().hasSinks() {
+		// The progress line is as wide as the line it overwrites,
+		// the rest of the line is only cleared when escape codes are written.
+		var line = "\r"
+		if l.Colorized {
+			line += "\x1b[K"
+		}
+		line += l.render(t, INFO, msg)
+		if fraction == 1 {
+			line += l.lineTerminator()
+		}
+		l.writeRaw(t, INFO, line, nil, true)
+		return
+	}
+	if !l.shared().progress.step(label, fraction, l.ProgressStep) {
+		return
+	}
+	l.writeRaw(t, INFO, l.render(t, INFO, lineOf(msg)), nil, false)
+}
+
+// step records the progress of the label, and reports whether a line should be written for it.
+func (p *progressTracker) step(label string, fraction, step float64) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if step <= 0 {
+		step = 0.1
+	}
+	var last, ok = p.written[label]
+	if ok && fraction == 1 && last == 1 {
+		// The task was already reported as done.
+		return false
+	}
+	if ok && fraction >= last && fraction < 1 && fraction-last < step-1e-9 {
+		return false
+	}
+	// A lower fraction than the last one starts the task again.
+	p.written[label] = fraction
+	return true
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestProgressSteps(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	for _, fraction := range []float64{0, 0.05, 0.1, 0.15, 0.5, 1, 1, 1} {
+		l.Progress("upload", fraction)
+	}
+	var lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Errorf("wrote %d lines, want 0%%, 10%%, 50%% and 100%%:\n%s", len(lines), buf.String())
+	}
+	if strings.Count(buf.String(), "100%") != 1 {
+		t.Errorf("the completion was written more than once:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	l.Progress("upload", 0)
+	l.Progress("upload", 1)
+	if strings.Count(buf.String(), "upload") != 2 {
+		t.Errorf("a restarted task was not written:\n%s", buf.String())
+	}
+}
+
+func TestProgressTerminal(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	IsTerminal = func(io.Writer) bool { return true }
+	l.Progress("upload", 0.5)
+	l.Info("interrupt")
+	l.Progress("upload", 1)
+
+	var out = buf.String()
+	if !strings.HasPrefix(out, "\r") {
+		t.Errorf("progress line does not overwrite the line:\n%q", out)
+	}
+	if strings.Contains(out, "\x1b") {
+		t.Errorf("escape codes are written without Colorized:\n%q", out)
+	}
+	if !strings.Contains(out, " 50%\n") || !strings.HasSuffix(out, "100%\n") {
+		t.Errorf("progress lines are not ended:\n%q", out)
+	}
+
+	buf.Reset()
+	l.Colorized = true
+	l.Progress("download", 0.5)
+	if !strings.HasPrefix(buf.String(), "\r\x1b[K") {
+		t.Errorf("colorized progress line does not clear the line:\n%q", buf.String())
+	}
+}
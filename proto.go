@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ProtoFormatter formats log entries as length-delimited protobuf records.
+//
+// The entry is marshalled into a protobuf message by the Marshal function, so this package does not depend on a protobuf library,
+// the formatter prefixes the message with its length as a varint, like the length-delimited messages of protobuf.
+// The records can be read back with ReadProtoRecord.
+//
+// This is a binary format, it does not pass ValidateFormatter.
+type ProtoFormatter struct {
+	// Marshal copies the time, level, message and stacktrace of the entry into a protobuf message,
+	// and returns the marshalled message, for example with proto.Marshal.
+	Marshal func(entry *LogEntry) ([]byte, error)
+
+	// FixedLength prefixes the records with their length as a 4-byte big-endian integer instead of a varint,
+	// framing them like the LengthPrefixedWriter.
+	//
+	// The records are framed already, so they should not be written through a LengthPrefixedWriter.
+	FixedLength bool
+
+	// OnError is called with the entries which could not be marshalled, those entries are not written.
+	OnError func(entry *LogEntry, err error)
+}
+
+// Format formats the log entry as a length-delimited protobuf record.
+func (f *ProtoFormatter) Format(entry *LogEntry) []byte {
+	var data, err = f.Marshal(entry)
+	if err != nil {
+		if f.OnError != nil {
+			f.OnError(entry, err)
+		}
+		return nil
+	}
+	if !f.FixedLength {
+		var record = make([]byte, 0, binary.MaxVarintLen64+len(data))
+		record = binary.AppendUvarint(record, uint64(len(data)))
+		return append(record, data...)
+	}
+	record, err := lengthPrefixed(data)
+	if err != nil {
+		if f.OnError != nil {
+			f.OnError(entry, err)
+		}
+		return nil
+	}
+	return record
+}
+
+// ValidateOutput checks if the output is a single record, of which the length matches the length prefix.
+func (f *ProtoFormatter) ValidateOutput(output []byte) error {
+	var length uint64
+	var prefix int
+	if f.FixedLength {
+		if len(output) < 4 {
+			return errors.New("record is shorter than its length prefix")
+		}
+		length, prefix = uint64(binary.BigEndian.Uint32(output)), 4
+	} else {
+		length, prefix = binary.Uvarint(output)
+		if prefix <= 0 {
+			return errors.New("record does not start with a valid length prefix")
+		}
+	}
+	if uint64(len(output)-prefix) != length {
+		return fmt.Errorf("length prefix is %d, but the record is %d bytes", length, len(output)-prefix)
+	}
+	return nil
+}
+
+// ReadProtoRecord reads a record written by the ProtoFormatter, and returns the marshalled protobuf message.
+//
+// The record must be prefixed with a varint, records written with FixedLength are not read.
+// io.EOF is returned when there are no more records, io.ErrUnexpectedEOF when a record is cut off.
+func ReadProtoRecord(r *bufio.Reader) ([]byte, error) {
+	var length, err = binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	var data = make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
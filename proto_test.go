@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// marshalTestProto stands in for proto.Marshal, the message ends with a newline to check it is kept.
+func marshalTestProto(entry *LogEntry) ([]byte, error) {
+	if entry.Message == "" {
+		return nil, errors.New("empty message")
+	}
+	return []byte(entry.Level.String() + ":" + entry.Message + "\n"), nil
+}
+
+func TestProtoFormatterRoundTrip(t *testing.T) {
+	var f = &ProtoFormatter{Marshal: marshalTestProto}
+	var buf = &bytes.Buffer{}
+	var messages = []string{"first", "second record", "third"}
+	for _, msg := range messages {
+		var record = f.Format(&LogEntry{Level: INFO, Message: msg})
+		if err := f.ValidateOutput(record); err != nil {
+			t.Fatalf("invalid record %q: %v", record, err)
+		}
+		buf.Write(record)
+	}
+
+	var r = bufio.NewReader(buf)
+	for _, msg := range messages {
+		var data, err = ReadProtoRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "INFO:" + msg + "\n"; string(data) != want {
+			t.Errorf("read %q, want %q", data, want)
+		}
+	}
+	if _, err := ReadProtoRecord(r); err != io.EOF {
+		t.Errorf("read after the last record returned %v, want io.EOF", err)
+	}
+}
+
+func TestProtoFormatterVarintFraming(t *testing.T) {
+	var f = &ProtoFormatter{Marshal: func(entry *LogEntry) ([]byte, error) { return []byte(entry.Message), nil }}
+	var message = strings.Repeat("x", 300)
+	var record = f.Format(&LogEntry{Message: message})
+	// 300 is written as a 2-byte varint, 0xac 0x02.
+	if !bytes.Equal(record[:2], []byte{0xac, 0x02}) || string(record[2:]) != message {
+		t.Errorf("record starts with %x, want the varint length 300", record[:2])
+	}
+}
+
+func TestProtoFormatterFixedLength(t *testing.T) {
+	var f = &ProtoFormatter{
+		Marshal:     func(entry *LogEntry) ([]byte, error) { return []byte(entry.Message), nil },
+		FixedLength: true,
+	}
+	var buf = &bytes.Buffer{}
+	NewLengthPrefixedWriter(buf).Write([]byte("payload"))
+	var record = f.Format(&LogEntry{Message: "payload"})
+	if !bytes.Equal(record, buf.Bytes()) {
+		t.Errorf("ProtoFormatter framed %q, LengthPrefixedWriter framed %q", record, buf.Bytes())
+	}
+	if err := f.ValidateOutput(record); err != nil {
+		t.Errorf("invalid record %q: %v", record, err)
+	}
+	if data, err := readLengthPrefixed(bytes.NewReader(record)); err != nil || string(data) != "payload" {
+		t.Errorf("read %q, %v, want the payload", data, err)
+	}
+}
+
+func TestProtoFormatterErrors(t *testing.T) {
+	var failed int
+	var f = &ProtoFormatter{
+		Marshal: marshalTestProto,
+		OnError: func(*LogEntry, error) { failed++ },
+	}
+	if record := f.Format(&LogEntry{}); record != nil || failed != 1 {
+		t.Errorf("failed marshal wrote %q and called OnError %d times", record, failed)
+	}
+
+	var record = f.Format(&LogEntry{Level: INFO, Message: "cut off"})
+	if _, err := ReadProtoRecord(bufio.NewReader(bytes.NewReader(record[:len(record)-1]))); err != io.ErrUnexpectedEOF {
+		t.Errorf("reading a cut off record returned %v, want io.ErrUnexpectedEOF", err)
+	}
+	if err := f.ValidateOutput(record[:len(record)-1]); err == nil {
+		t.Error("ValidateOutput accepted a cut off record")
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+// RenderHint tells formatters in which output formats a field is included.
+type RenderHint int
+
+const (
+	// RenderAlways includes the field in all formats.
+	RenderAlways RenderHint = iota
+
+	// RenderTextOnly only includes the field in text formats, such as the lines of the Logger and logfmt.
+	RenderTextOnly
+
+	// RenderJSONOnly only includes the field in JSON formats, such as the JSONFormatter and ECSFormatter.
+	RenderJSONOnly
+)
+
+// Hinter is implemented by field values which are only included in some output formats.
+type Hinter interface {
+	RenderHint() RenderHint
+}
+
+// Hint wraps a field value, so it is only included in the output formats of the render hint.
+//
+// For example, a verbose value can be kept out of the console, while it is still written to JSON:
+//
+//	l.WithFields(logger.NewFields("request", logger.Hint(req, logger.RenderJSONOnly)))
+func Hint(value any, hint RenderHint) any {
+	return hinted{value: value, hint: hint}
+}
+
+// A field value wrapped by Hint.
+type hinted struct {
+	value any
+	hint  RenderHint
+}
+
+func (h hinted) RenderHint() RenderHint {
+	return h.hint
+}
+
+func (h hinted) String() string {
+	return formatValue(h.value)
+}
+
+func (h hinted) MarshalJSON() ([]byte, error) {
+	return marshalValue(h.value), nil
+}
+
+// omitted reports whether the field value is left out of the format,
+// which is either RenderTextOnly or RenderJSONOnly.
+func omitted(value any, format RenderHint) bool {
+	var h, ok = value.(Hinter)
+	if !ok {
+		return false
+	}
+	var hint = h.RenderHint()
+	return hint != RenderAlways && hint != format
+}
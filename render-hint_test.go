@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// A field value which is only written to the console.
+type consoleArt string
+
+func (consoleArt) RenderHint() RenderHint { return RenderTextOnly }
+
+func TestRenderHints(t *testing.T) {
+	var f = NewFields(
+		"request", Hint(map[string]int{"id": 1}, RenderJSONOnly),
+		"art", consoleArt("<*>"),
+		"user", 42,
+	)
+	if got, want := f.String(), "art=<*> user=42"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+	var data, err = json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"request":{"id":1},"user":42}`; got != want {
+		t.Errorf("json: got %s, want %s", got, want)
+	}
+
+	var l, buf = newTestLogger(t, DEBUG)
+	l.WithFields(f).Info("request")
+	if strings.Contains(buf.String(), "id") {
+		t.Errorf("JSON-only field is written to the console: %q", buf.String())
+	}
+	buf.Reset()
+	l.Formatter = &JSONFormatter{}
+	l.WithFields(f).Info("request")
+	if !strings.Contains(buf.String(), `"request":{"id":1}`) || strings.Contains(buf.String(), "art") {
+		t.Errorf("unexpected JSON output %s", buf.String())
+	}
+}
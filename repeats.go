@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// repeatTracker remembers the last error, to summarize repeats of it.
+type repeatTracker struct {
+	mutex *sync.Mutex
+
+	// The loglevel and message of the last error, the time its stacktrace was written,
+	// and the amount of times it was repeated since.
+	level   Loglevel
+	message string
+	first   time.Time
+	count   int
+}
+
+func newRepeatTracker() *repeatTracker {
+	return &repeatTracker{mutex: &sync.Mutex{}}
+}
+
+// track records an error message, and returns how many times it was repeated within the window.
+//
+// Zero is returned when the stacktrace of the error should be written.
+func (r *repeatTracker) track(now time.Time, level Loglevel, message string, window time.Duration) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if level != r.level || message != r.message || now.Sub(r.first) >= window {
+		r.level = level
+		r.message = message
+		r.first = now
+		r.count = 0
+		return 0
+	}
+	r.count++
+	return r.count
+}
+
+// logRepeat reports whether the error is a repeat of the last error of the level within the RepeatWindow,
+// and writes a summary line instead of the error if so.
+func (l *Logger) logRepeat(level Loglevel, err error) bool {
+	if l.RepeatWindow <= 0 || !l.enabled(level) {
+		return false
+	}
+	var n = l.shared().repeats.track(l.now(), level, err.Error(), l.RepeatWindow)
+	if n == 0 {
+		return false
+	}
+	l.logLine(level, fmt.Sprintf("%s (same error as above, +%d times)", err.Error(), n))
+	return true
+}
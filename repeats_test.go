@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRepeatWindow(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var now = testTime
+	l.Clock = func() time.Time { return now }
+	l.RepeatWindow = time.Minute
+
+	l.Errorf("connection %s refused", "db")
+	l.Errorf("connection %s refused", "db")
+	l.Error("connection db refused")
+	l.Critical(errors.New("connection db refused"))
+
+	var out = buf.String()
+	if n := strings.Count(out, "Stacktrace:"); n != 2 {
+		t.Errorf("wrote %d stacktraces, want one for the error and one for the critical error:\n%s", n, out)
+	}
+	if !strings.Contains(out, "connection db refused (same error as above, +2 times)") {
+		t.Errorf("missing the summary of the repeats:\n%s", out)
+	}
+
+	buf.Reset()
+	now = now.Add(time.Minute)
+	l.Errorf("connection %s refused", "db")
+	if !strings.Contains(buf.String(), "Stacktrace:") {
+		t.Errorf("error after the RepeatWindow has no stacktrace:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// The width of the rule written between requests by SeparateRequests.
+const requestSeparatorWidth = 40
+
+// SeparateRequests wraps the handler to write a dimmed rule between two requests when RequestSeparator is set,
+// so the lines of one request can be told apart from those of the next.
+//
+// The rule is only written by a colorized logger without a Formatter, it is not written before the first request.
+//
+//	http.ListenAndServe(":8080", l.SeparateRequests(mux))
+func (l *Logger) SeparateRequests(next http.Handler) http.Handler {
+	var served atomic.Bool
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served.Swap(true) && l.RequestSeparator && l.Colorized && l.Formatter == nil && l.enabled(INFO) {
+			var t = l.now()
+			l.writeRaw(t, INFO, Colorize(strings.Repeat("-", requestSeparatorWidth), DimGrey)+l.lineTerminator(), nil, false)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
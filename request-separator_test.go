@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSeparateRequests(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.Colorized = true
+	l.RequestSeparator = true
+	var handler = l.SeparateRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Info(r.URL.Path)
+	}))
+	var serve = func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/first", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/second", nil))
+	}
+
+	serve()
+	var rule = Colorize(strings.Repeat("-", requestSeparatorWidth), DimGrey) + "\n"
+	var out = buf.String()
+	if strings.Count(out, rule) != 1 {
+		t.Errorf("got %d separators, want one between the two requests:\n%q", strings.Count(out, rule), out)
+	}
+	var first, separator, second = strings.Index(out, "/first"), strings.Index(out, rule), strings.Index(out, "/second")
+	if !(first < separator && separator < second) {
+		t.Errorf("separator is not between the requests:\n%q", out)
+	}
+	if strings.HasPrefix(out, rule) {
+		t.Errorf("separator is written before the first request:\n%q", out)
+	}
+
+	buf.Reset()
+	l.Formatter = &JSONFormatter{}
+	serve()
+	if strings.Contains(buf.String(), "---") {
+		t.Errorf("separator is written with a Formatter:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	l.Formatter = nil
+	l.RequestSeparator = false
+	serve()
+	if strings.Contains(buf.String(), "---") {
+		t.Errorf("separator is written without RequestSeparator:\n%s", buf.String())
+	}
+}
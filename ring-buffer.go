@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// RingBufferWriter is a writer which keeps the most recent lines written to it in memory.
+//
+// When more lines are written than the size of the buffer, the oldest lines are dropped.
+//
+// It is safe for concurrent use.
+type RingBufferWriter struct {
+	// The lines in the buffer, start is the index of the oldest line.
+	lines []string
+	start int
+	count int
+
+	// Data written after the last newline, waiting for the rest of the line.
+	partial []byte
+
+	// The mutex used to lock the buffer.
+	mutex *sync.RWMutex
+}
+
+// NewRingBufferWriter creates a new RingBufferWriter which keeps at most size lines.
+func NewRingBufferWriter(size int) *RingBufferWriter {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingBufferWriter{
+		lines: make([]string, size),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// Write writes p to the buffer.
+//
+// A line is only added to the buffer once its newline has been written.
+func (rb *RingBufferWriter) Write(p []byte) (int, error) {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	var data = p
+	for {
+		var i = bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		var line = string(rb.partial) + string(data[:i+1])
+		rb.partial = rb.partial[:0]
+		rb.push(line)
+		data = data[i+1:]
+	}
+	rb.partial = append(rb.partial, data...)
+	return len(p), nil
+}
+
+// Lines returns a copy of the lines in the buffer, oldest first.
+func (rb *RingBufferWriter) Lines() []string {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	var lines = make([]string, 0, rb.count)
+	for i := 0; i < rb.count; i++ {
+		lines = append(lines, rb.lines[(rb.start+i)%len(rb.lines)])
+	}
+	return lines
+}
+
+// Len returns the amount of lines in the buffer.
+func (rb *RingBufferWriter) Len() int {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	return rb.count
+}
+
+func (rb *RingBufferWriter) push(line string) {
+	if rb.count < len(rb.lines) {
+		rb.lines[(rb.start+rb.count)%len(rb.lines)] = line
+		rb.count++
+		return
+	}
+	rb.lines[rb.start] = line
+	rb.start = (rb.start + 1) % len(rb.lines)
+}
+
+// ByteRingBufferWriter is a writer which keeps the most recent lines written to it in memory,
+// bounded by the total size of the lines in bytes instead of the amount of lines.
+//
+// When the size is exceeded, the oldest whole lines are dropped; lines are never split.
+// A single line larger than the maximum size is not kept.
+//
+// It is safe for concurrent use.
+type ByteRingBufferWriter struct {
+	// The maximum total size of the lines in bytes.
+	maxSize int
+
+	// The lines in the buffer, start is the index of the oldest line.
+	// The slice grows when it is full, evicted lines are cleared so they can be garbage collected.
+	lines []string
+	start int
+	count int
+	size  int
+
+	// Data written after the last newline, waiting for the rest of the line.
+	//
+	// It never holds more than the maximum size: once a line is larger, it can not be kept,
+	// so the rest of it is discarded until its newline.
+	partial    []byte
+	discarding bool
+
+	// The mutex used to lock the buffer.
+	mutex *sync.RWMutex
+}
+
+// NewByteRingBufferWriter creates a new ByteRingBufferWriter which keeps at most maxSize bytes.
+func NewByteRingBufferWriter(maxSize int) *ByteRingBufferWriter {
+	return &ByteRingBufferWriter{
+		maxSize: maxSize,
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Write writes p to the buffer.
+//
+// A line is only added to the buffer once its newline has been written.
+func (rb *ByteRingBufferWriter) Write(p []byte) (int, error) {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	var data = p
+	for {
+		var i = bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if rb.discarding {
+			rb.discarding = false
+		} else if len(rb.partial)+i+1 <= rb.maxSize {
+			rb.push(string(rb.partial) + string(data[:i+1]))
+		}
+		rb.partial = rb.partial[:0]
+		data = data[i+1:]
+	}
+	if rb.discarding {
+		return len(p), nil
+	}
+	if len(rb.partial)+len(data) > rb.maxSize {
+		rb.partial = rb.partial[:0]
+		rb.discarding = true
+		return len(p), nil
+	}
+	rb.partial = append(rb.partial, data...)
+	return len(p), nil
+}
+
+// Lines returns a copy of the lines in the buffer, oldest first.
+func (rb *ByteRingBufferWriter) Lines() []string {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	var lines = make([]string, 0, rb.count)
+	for i := 0; i < rb.count; i++ {
+		lines = append(lines, rb.lines[(rb.start+i)%len(rb.lines)])
+	}
+	return lines
+}
+
+// Bytes returns the lines in the buffer joined together, oldest first.
+func (rb *ByteRingBufferWriter) Bytes() []byte {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	var b = make([]byte, 0, rb.size)
+	for i := 0; i < rb.count; i++ {
+		b = append(b, rb.lines[(rb.start+i)%len(rb.lines)]...)
+	}
+	return b
+}
+
+// Size returns the total size of the lines in the buffer in bytes.
+func (rb *ByteRingBufferWriter) Size() int {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	return rb.size
+}
+
+func (rb *ByteRingBufferWriter) push(line string) {
+	for rb.count > 0 && rb.size+len(line) > rb.maxSize {
+		rb.size -= len(rb.lines[rb.start])
+		rb.lines[rb.start] = ""
+		rb.start = (rb.start + 1) % len(rb.lines)
+		rb.count--
+	}
+	if rb.count == len(rb.lines) {
+		rb.grow()
+	}
+	rb.lines[(rb.start+rb.count)%len(rb.lines)] = line
+	rb.count++
+	rb.size += len(line)
+}
+
+// grow doubles the room for lines, moving the oldest line to the start.
+func (rb *ByteRingBufferWriter) grow() {
+	var size = 2 * len(rb.lines)
+	if size == 0 {
+		size = 16
+	}
+	var lines = make([]string, size)
+	for i := 0; i < rb.count; i++ {
+		lines[i] = rb.lines[(rb.start+i)%len(rb.lines)]
+	}
+	rb.lines = lines
+	rb.start = 0
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRingBufferWriter(t *testing.T) {
+	var rb = NewRingBufferWriter(2)
+	fmt.Fprint(rb, "one\ntwo\nthr")
+	fmt.Fprint(rb, "ee\n")
+	if got, want := rb.Lines(), []string{"two\n", "three\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines are %q, want %q", got, want)
+	}
+}
+
+func TestByteRingBufferWriterEvictsOldest(t *testing.T) {
+	var rb = NewByteRingBufferWriter(10)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(rb, "%03d\n", i)
+	}
+	if got, want := rb.Lines(), []string{"098\n", "099\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines are %q, want %q", got, want)
+	}
+	if got := string(rb.Bytes()); got != "098\n099\n" {
+		t.Errorf("bytes are %q", got)
+	}
+	if rb.Size() != 8 {
+		t.Errorf("size is %d, want 8", rb.Size())
+	}
+	if len(rb.lines) > 16 {
+		t.Errorf("room for %d lines, want it to stay small", len(rb.lines))
+	}
+}
+
+func TestByteRingBufferWriterLongLine(t *testing.T) {
+	var rb = NewByteRingBufferWriter(10)
+	fmt.Fprint(rb, "kept\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprint(rb, strings.Repeat("x", 7))
+	}
+	if len(rb.partial) > 10 {
+		t.Errorf("partial line grew to %d bytes", len(rb.partial))
+	}
+	fmt.Fprint(rb, "end\nnext\n")
+	if got, want := rb.Lines(), []string{"kept\n", "next\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines are %q, want %q", got, want)
+	}
+}
+
+func BenchmarkByteRingBufferWriter(b *testing.B) {
+	var rb = NewByteRingBufferWriter(1 << 16)
+	var line = []byte(strings.Repeat("x", 99) + "\n")
+	b.SetBytes(int64(len(line)))
+	for i := 0; i < b.N; i++ {
+		rb.Write(line)
+	}
+}
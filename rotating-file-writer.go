@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileWriter writes to a dated file next to its path, and starts a new file every day,
+// and when the file would grow over the maximum size.
+//
+// For the path "logs/app.log", the files are named "logs/app-2024-01-15.log", "logs/app-2024-01-15.1.log" and so on.
+// The path itself is a symlink to the current file, so log shippers and humans can follow a stable path.
+// On platforms where the symlink can not be created, such as Windows without the privilege to create symlinks,
+// the files are written without it.
+//
+// It is safe for concurrent use.
+type RotatingFileWriter struct {
+	// Clock returns the current time, which decides the day of the file, defaults to time.Now.
+	Clock func() time.Time
+
+	// The path of the symlink, and the maximum size of a file in bytes.
+	path    string
+	maxSize int64
+
+	// The current file, the day it was opened for, its number within the day and its size.
+	file  *os.File
+	day   string
+	index int
+	size  int64
+
+	// The mutex used to lock the writer.
+	mutex *sync.Mutex
+}
+
+// NewRotatingFileWriter creates a new RotatingFileWriter for the path, which starts a new file once a file would exceed maxSize bytes.
+//
+// A maxSize <= 0 only starts a new file every day. The file of the current day is opened on the first write.
+func NewRotatingFileWriter(path string, maxSize int64) *RotatingFileWriter {
+	return &RotatingFileWriter{
+		path:    path,
+		maxSize: maxSize,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Write writes p to the current file, after rotating it if the day has changed or it would exceed the maximum size.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	var now = time.Now()
+	if w.Clock != nil {
+		now = w.Clock()
+	}
+	var day = now.Format("2006-01-02")
+	switch {
+	case w.file == nil || day != w.day:
+		if err := w.open(day, 0); err != nil {
+			return 0, err
+		}
+	case w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize:
+		if err := w.open(day, w.index+1); err != nil {
+			return 0, err
+		}
+	}
+	var n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Current returns the path of the file which is currently written to, or an empty string before the first write.
+func (w *RotatingFileWriter) Current() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return ""
+	}
+	return w.file.Name()
+}
+
+// Sync commits the current file to disk.
+func (w *RotatingFileWriter) Sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file, a later write opens it again.
+func (w *RotatingFileWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	var err = w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open closes the current file, and opens the file of the day with the index, or the next one which is not full.
+func (w *RotatingFileWriter) open(day string, index int) error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+	var ext = filepath.Ext(w.path)
+	var base = strings.TrimSuffix(w.path, ext)
+	for {
+		var name = fmt.Sprintf("%s-%s%s", base, day, ext)
+		if index > 0 {
+			name = fmt.Sprintf("%s-%s.%d%s", base, day, index, ext)
+		}
+		var file, err = NewLogFile(name)
+		if err != nil {
+			return err
+		}
+		var info, statErr = file.Stat()
+		if statErr == nil && w.maxSize > 0 && info.Size() >= w.maxSize {
+			// The file was filled before the writer was created, such as before a restart.
+			file.Close()
+			index++
+			continue
+		}
+		w.file, w.day, w.index = file, day, index
+		w.size = 0
+		if statErr == nil {
+			w.size = info.Size()
+		}
+		w.link(name)
+		return nil
+	}
+}
+
+// link points the symlink at the path to the file.
+//
+// The symlink is created next to it and renamed over the path, so the path never is missing.
+// Errors are ignored, the files are still written when symlinks are not supported.
+func (w *RotatingFileWriter) link(name string) {
+	var tmp = w.path + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(name), tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, w.path); err != nil {
+		os.Remove(tmp)
+	}
+}
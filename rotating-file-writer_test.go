@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need a privilege on windows")
+	}
+	var path = filepath.Join(t.TempDir(), "app.log")
+	var now = time.Date(2024, 1, 15, 23, 59, 0, 0, time.UTC)
+	var w = NewRotatingFileWriter(path, 20)
+	w.Clock = func() time.Time { return now }
+	defer w.Close()
+
+	var linked = func(want string) {
+		t.Helper()
+		var target, err = os.Readlink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target != want {
+			t.Errorf("symlink points at %q, want %q", target, want)
+		}
+		if data, err := os.ReadFile(path); err != nil || len(data) == 0 {
+			t.Errorf("symlink does not resolve to the written file: %v", err)
+		}
+	}
+
+	w.Write([]byte("first line\n"))
+	linked("app-2024-01-15.log")
+
+	// The second line does not fit in the file anymore.
+	w.Write([]byte("second line\n"))
+	linked("app-2024-01-15.1.log")
+
+	now = now.Add(time.Minute)
+	w.Write([]byte("next day\n"))
+	linked("app-2024-01-16.log")
+	if w.Current() != filepath.Join(filepath.Dir(path), "app-2024-01-16.log") {
+		t.Errorf("current file is %q", w.Current())
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "app-2024-01-15.log")); string(data) != "first line\n" {
+		t.Errorf("rotated file holds %q", data)
+	}
+}
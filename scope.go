@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// loggerState holds the state which is shared with copies of a logger, as they share its writer.
+type loggerState struct {
+	// Serializes the writes of the logger, so lines of concurrent calls do not interleave.
+	mutex *sync.Mutex
+
+	// Tracks the lines written per second.
+	volume *volumeTracker
+
+	// Remembers the last error, for the RepeatWindow.
+	repeats *repeatTracker
+
+	// Tracks the progress lines.
+	progress *progressTracker
+
+	// Counts the bytes written for the MaxTotalBytes.
+	output *outputCap
+
+	// Remembers the expiry warnings written by WarnExpiring.
+	expiry *expiryTracker
+
+	// Writes the header of the AuditFormatter before the first audit event.
+	auditHeader *sync.Once
+
+	// The sinks which the lines are written to instead of the writer of the logger, guarded by the mutex.
+	sinks []Sink
+
+	// Whether one of the sinks has a Formatter, so the entries of all lines are needed.
+	formattedSinks *atomic.Bool
+}
+
+func newLoggerState() *loggerState {
+	return &loggerState{
+		mutex:          &sync.Mutex{},
+		volume:         newVolumeTracker(),
+		repeats:        newRepeatTracker(),
+		progress:       newProgressTracker(),
+		output:         newOutputCap(),
+		expiry:         newExpiryTracker(),
+		auditHeader:    &sync.Once{},
+		formattedSinks: &atomic.Bool{},
+	}
+}
+
+// The state and scope of loggers which were not created with NewLogger, such as a Logger{} literal.
+//
+// They are shared by all such loggers, so the fields of a logger are never written after it is created.
+var (
+	zeroState = newLoggerState()
+	zeroScope = newLoggerScope(nil)
+)
+
+// loggerScope holds the state which belongs to one logger, and is not shared with copies of the logger.
+//
+// A derived logger, such as the one returned by WithFields, gets a scope of its own,
+// which refers to the scope of the logger it was derived from.
+type loggerScope struct {
+	parent *loggerScope
+
+	// Counts the mutes of the logger.
+	mutes *muteTracker
+
+	// Collects the lines written during Capture.
+	captures *captureTracker
+
+	// Delivers the written entries to the channels of Subscribe.
+	subscribers *subscriberTracker
+}
+
+func newLoggerScope(parent *loggerScope) *loggerScope {
+	return &loggerScope{
+		parent:      parent,
+		mutes:       newMuteTracker(),
+		captures:    newCaptureTracker(),
+		subscribers: newSubscriberTracker(),
+	}
+}
+
+// muted reports whether the logger of the scope, or a logger it was derived from, is muted.
+func (s *loggerScope) muted() bool {
+	for ; s != nil; s = s.parent {
+		if s.mutes.muted() {
+			return true
+		}
+	}
+	return false
+}
+
+// capture adds a written line to the running captures of the scope, and of the scopes it was derived from.
+func (s *loggerScope) capture(line string) {
+	for ; s != nil; s = s.parent {
+		s.captures.add(line)
+	}
+}
+
+// subscribed reports whether the scope, or a scope it was derived from, has any subscribers.
+func (s *loggerScope) subscribed() bool {
+	for ; s != nil; s = s.parent {
+		if s.subscribers.active() {
+			return true
+		}
+	}
+	return false
+}
+
+// publish delivers the entry to the subscribers of the scope, and of the scopes it was derived from.
+func (s *loggerScope) publish(entry *LogEntry) {
+	for ; s != nil; s = s.parent {
+		if s.subscribers.active() {
+			s.subscribers.publish(entry)
+		}
+	}
+}
+
+// shared returns the state shared with copies of the logger.
+func (l *Logger) shared() *loggerState {
+	if l.state == nil {
+		return zeroState
+	}
+	return l.state
+}
+
+// ownScope returns the scope of the logger.
+func (l *Logger) ownScope() *loggerScope {
+	if l.scope == nil {
+		return zeroScope
+	}
+	return l.scope
+}
+
+// derive returns a copy of the logger with a scope of its own.
+func (l *Logger) derive() *Logger {
+	var clone = *l
+	clone.scope = newLoggerScope(l.ownScope())
+	return &clone
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer which is safe for concurrent writes.
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// Run with -race, the loggers are used from many goroutines right after they are created.
+func TestConcurrentUse(t *testing.T) {
+	restoreGlobals(t)
+	var loggers = map[string]*Logger{
+		"NewLogger": NewLogger(DEBUG, &syncBuffer{}),
+		"zero":      {File: &syncBuffer{}},
+	}
+	for name, l := range loggers {
+		t.Run(name, func(t *testing.T) {
+			l.MaxTotalBytes = 1 << 20
+			l.VolumeWarnThreshold = 1000
+			l.RepeatWindow = 1
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					var child = l.WithFields(NewFields("i", i))
+					var entries, unsubscribe = child.Subscribe()
+					defer unsubscribe()
+					child.Capture(func() {
+						var restore = child.Mute()
+						child.Info("muted")
+						restore()
+						child.Info("hello")
+						child.Critical(errors.New("boom"))
+						l.Progress("task", float64(i)/100)
+						l.Audit("event", nil)
+					})
+					for len(entries) > 0 {
+						<-entries
+					}
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// Run with -race, multi-line messages and stacktraces are written from 100 goroutines at once.
+func TestConcurrentLinesIntact(t *testing.T) {
+	restoreGlobals(t)
+	var buf = &syncBuffer{}
+	var l = NewLogger(DEBUG, buf)
+	l.Colorized = false
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.Info(fmt.Sprintf("g%03d first\ng%03d second\ng%03d third", i, i, i))
+			l.Error(errors.New("boom"))
+		}(i)
+	}
+	wg.Wait()
+
+	var lines = strings.Split(buf.String(), "\n")
+	for i := 0; i < 100; i++ {
+		var first = fmt.Sprintf("g%03d first", i)
+		var n = -1
+		for j, line := range lines {
+			if strings.HasSuffix(line, "] "+first) {
+				n = j
+				break
+			}
+		}
+		if n < 0 || n+2 >= len(lines) || lines[n+1] != fmt.Sprintf("g%03d second", i) || !strings.HasPrefix(lines[n+2], fmt.Sprintf("g%03d third", i)) {
+			t.Fatalf("message of goroutine %d is not intact:\n%s", i, buf.String())
+		}
+	}
+	var stacktraces int
+	for j, line := range lines {
+		if line != "Stacktrace:" {
+			continue
+		}
+		stacktraces++
+		var k = j + 1
+		for k < len(lines) && strings.HasPrefix(lines[k], "Error on line") {
+			k++
+		}
+		if k == j+1 || k == len(lines) || strings.Trim(lines[k], "-") != "" {
+			t.Fatalf("stacktrace at line %d is interleaved:\n%s", j, strings.Join(lines[j:k+1], "\n"))
+		}
+	}
+	if stacktraces != 100 {
+		t.Errorf("found %d stacktraces, want 100", stacktraces)
+	}
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// A writer which buffers data, such as AutoFlushWriter or bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// flushWriter flushes the writer if it buffers data.
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Flush flushes the writer of the logger, if it buffers data.
+func (l *Logger) Flush() error {
+	return flushWriter(l.File)
+}
+
+// A writer which can commit its data to stable storage, such as *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// A writer which wraps another writer, such as AutoFlushWriter.
+type unwrapper interface {
+	Unwrap() io.Writer
+}
+
+// Sync flushes the writer of the logger and commits the written data to disk,
+// so it is not lost when the system crashes.
+//
+// Writers which wrap another writer are flushed and unwrapped until a writer with a Sync method, such as *os.File, is found.
+// For other writers, Sync only flushes.
+func (l *Logger) Sync() error {
+	return syncWriter(l.File)
+}
+
+// FlushAndWait flushes the writer of the logger and waits until the written data is on disk, see Sync.
+//
+// The Logger writes synchronously, so all lines written before the call are included.
+// This is meant for the teardown of tests, before the output is checked.
+func (l *Logger) FlushAndWait() error {
+	return l.Sync()
+}
+
+// syncWriter flushes and unwraps the writer until it can be synced, see Logger.Sync.
+func syncWriter(w io.Writer) error {
+	for w != nil {
+		if err := flushWriter(w); err != nil {
+			return err
+		}
+		if s, ok := w.(syncer); ok {
+			return s.Sync()
+		}
+		var u, ok = w.(unwrapper)
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+	return nil
+}
+
+// InstallShutdownFlush flushes the writer of the logger when the process receives SIGINT or SIGTERM.
+//
+// After the flush, the default behaviour of the signal is restored and the signal is sent to the process again,
+// so the process still terminates, see TriggerShutdownFlush.
+//
+// The returned function removes the flush.
+func (l *Logger) InstallShutdownFlush() (uninstall func()) {
+	return installShutdownFlush(func() { l.Flush() })
+}
+
+// FlushOnPanic flushes the writer of the logger when the program panics, and then continues panicking.
+//
+// It must be deferred, for example at the top of main:
+//
+//	defer l.FlushOnPanic()
+func (l *Logger) FlushOnPanic() {
+	if r := recover(); r != nil {
+		l.Flush()
+		panic(r)
+	}
+}
+
+// Flush flushes the batched log entries, and the writer of the logger if it buffers data.
+func (l *BatchLogger) Flush() error {
+	l.batcher.Flush()
+	return flushWriter(l.File)
+}
+
+// FlushAndWait writes all batched log entries, and waits until they are on disk, see Logger.Sync.
+//
+// Unlike Flush, it waits for a flush which is already in progress, so all entries logged before the call are included.
+// This is meant for the teardown of tests, before the output is checked.
+func (l *BatchLogger) FlushAndWait() error {
+	l.batcher.FlushSync()
+	return syncWriter(l.File)
+}
+
+// InstallShutdownFlush flushes the batched log entries and the writer of the logger
+// when the process receives SIGINT or SIGTERM.
+//
+// After the flush, the default behaviour of the signal is restored and the signal is sent to the process again,
+// so the process still terminates, see TriggerShutdownFlush.
+//
+// The returned function removes the flush.
+func (l *BatchLogger) InstallShutdownFlush() (uninstall func()) {
+	return installShutdownFlush(func() { l.Flush() })
+}
+
+// FlushOnPanic flushes the batched log entries when the program panics, and then continues panicking.
+//
+// It must be deferred, for example at the top of main:
+//
+//	defer l.FlushOnPanic()
+func (l *BatchLogger) FlushOnPanic() {
+	if r := recover(); r != nil {
+		l.Flush()
+		panic(r)
+	}
+}
+
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+var (
+	shutdownMutex   = &sync.Mutex{}
+	shutdownFlushes = map[int]func(){}
+	shutdownNextID  int
+	// The channel the shutdown signals are delivered on, while any flush is installed.
+	shutdownSignalChan chan os.Signal
+)
+
+// raise sends the signal to the process, tests replace it so the test binary is not terminated.
+var raise = func(sig os.Signal) error {
+	var p, err = os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
+
+func installShutdownFlush(flush func()) (uninstall func()) {
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+	var id = shutdownNextID
+	shutdownNextID++
+	shutdownFlushes[id] = flush
+	if shutdownSignalChan == nil {
+		shutdownSignalChan = make(chan os.Signal, 1)
+		signal.Notify(shutdownSignalChan, shutdownSignals...)
+		go waitForShutdown(shutdownSignalChan)
+	}
+	return func() {
+		shutdownMutex.Lock()
+		defer shutdownMutex.Unlock()
+		delete(shutdownFlushes, id)
+		if len(shutdownFlushes) == 0 {
+			stopShutdownSignals()
+		}
+	}
+}
+
+// stopShutdownSignals stops the delivery of the shutdown signals, the shutdownMutex must be held by the caller.
+func stopShutdownSignals() {
+	if shutdownSignalChan == nil {
+		return
+	}
+	signal.Stop(shutdownSignalChan)
+	close(shutdownSignalChan)
+	shutdownSignalChan = nil
+}
+
+// waitForShutdown runs the installed flushes when a shutdown signal is received.
+func waitForShutdown(signals <-chan os.Signal) {
+	if sig, ok := <-signals; ok {
+		TriggerShutdownFlush(sig)
+	}
+}
+
+// TriggerShutdownFlush runs the flushes installed with InstallShutdownFlush, as if the process received the signal.
+//
+// The flushes are removed, the default behaviour of the signal is restored with signal.Reset,
+// and the signal is sent to the process again so it is not swallowed by the flush.
+// For SIGINT and SIGTERM, this terminates the process.
+func TriggerShutdownFlush(sig os.Signal) {
+	shutdownMutex.Lock()
+	var flushes = shutdownFlushes
+	shutdownFlushes = map[int]func(){}
+	stopShutdownSignals()
+	shutdownMutex.Unlock()
+
+	for _, flush := range flushes {
+		flush()
+	}
+	signal.Reset(sig)
+	raise(sig)
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeRaise replaces the raise of a signal for the test, and returns the raised signals.
+func fakeRaise(t *testing.T) *[]os.Signal {
+	var raised []os.Signal
+	var original = raise
+	raise = func(sig os.Signal) error {
+		raised = append(raised, sig)
+		return nil
+	}
+	t.Cleanup(func() { raise = original })
+	return &raised
+}
+
+func TestTriggerShutdownFlush(t *testing.T) {
+	var raised = fakeRaise(t)
+	var buf = &bytes.Buffer{}
+	var l = NewLogger(DEBUG, NewAutoFlushWriter(buf, 4096, time.Hour))
+	defer l.InstallShutdownFlush()()
+	l.Info("before the signal")
+	if buf.Len() != 0 {
+		t.Fatalf("line was written before the flush: %q", buf.String())
+	}
+
+	TriggerShutdownFlush(syscall.SIGTERM)
+	if !strings.Contains(buf.String(), "before the signal") {
+		t.Errorf("buffer was not flushed by the signal: %q", buf.String())
+	}
+	if len(*raised) != 1 || (*raised)[0] != syscall.SIGTERM {
+		t.Errorf("raised %v after the flush, want the SIGTERM to be raised again", *raised)
+	}
+}
+
+func TestTriggerShutdownFlushRemovesFlushes(t *testing.T) {
+	var raised = fakeRaise(t)
+	var flushes int
+	var uninstall = installShutdownFlush(func() { flushes++ })
+	defer uninstall()
+	var removed = installShutdownFlush(func() { t.Error("uninstalled flush was run") })
+	removed()
+
+	TriggerShutdownFlush(os.Interrupt)
+	TriggerShutdownFlush(os.Interrupt)
+	if flushes != 1 {
+		t.Errorf("flushed %d times, want once", flushes)
+	}
+	if len(*raised) != 2 {
+		t.Errorf("raised %d signals, want 2", len(*raised))
+	}
+}
+
+func TestSyncFlushesFile(t *testing.T) {
+	var f, err = os.Create(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var w = NewAutoFlushWriter(f, 4096, time.Hour)
+	defer w.Close()
+	var l, _ = newTestLogger(t, DEBUG)
+	l.File = w
+	l.Info("committed")
+
+	if data, _ := os.ReadFile(f.Name()); len(data) != 0 {
+		t.Fatalf("line was written before Sync: %q", data)
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatalf("Sync returned %v", err)
+	}
+	if data, _ := os.ReadFile(f.Name()); !strings.Contains(string(data), "[INFO] committed") {
+		t.Errorf("buffer was not flushed by Sync: %q", data)
+	}
+}
+
+func TestSyncOtherWriter(t *testing.T) {
+	var l = NewLogger(DEBUG, &bytes.Buffer{})
+	if err := l.Sync(); err != nil {
+		t.Errorf("Sync of a buffer returned %v", err)
+	}
+}
+
+func TestFlushAndWait(t *testing.T) {
+	var buf = &syncBuffer{}
+	var w = NewAutoFlushWriter(buf, 1<<16, time.Hour)
+	defer w.Close()
+	var l, _ = newTestLogger(t, DEBUG)
+	l.File = w
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.Infof("line %d\n", i)
+		}(i)
+	}
+	wg.Wait()
+	if buf.String() != "" {
+		t.Fatalf("lines were flushed before FlushAndWait:\n%s", buf.String())
+	}
+	if err := l.FlushAndWait(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(buf.String(), fmt.Sprintf("line %d\n", i)) {
+			t.Errorf("line %d is missing after FlushAndWait:\n%s", i, buf.String())
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"io"
+	"reflect"
+)
+
+// Sink is a destination of the lines of a logger, see SetSinks.
+type Sink struct {
+	// Writer receives the lines written to the sink.
+	Writer io.Writer
+
+	// Loglevel is the least severe loglevel of the lines written to the sink.
+	//
+	// If zero, lines of any loglevel are written to the sink.
+	Loglevel Loglevel
+
+	// MostSevere is the most severe loglevel of the lines written to the sink,
+	// such as WARNING to write errors to another sink only.
+	//
+	// If zero, lines of any severity are written to the sink.
+	MostSevere Loglevel
+
+	// Formatter formats the lines written to the sink, such as a JSONFormatter for an alerting pipeline.
+	//
+	// If nil, the sink gets the lines as they are rendered by the logger.
+	// Lines without a log entry, such as progress lines and notices of the logger, are not written to a sink with a Formatter.
+	Formatter Formatter
+}
+
+// allows reports whether lines with the loglevel are written to the sink.
+func (s Sink) allows(level Loglevel) bool {
+	return (s.Loglevel == 0 || level.AtLeast(s.Loglevel)) && (s.MostSevere == 0 || s.MostSevere.AtLeast(level))
+}
+
+// SetSinks replaces the writers of the logger with the sinks, for example to reconfigure the output when the configuration is reloaded.
+// Each line is then written to all sinks which allow its loglevel, instead of to the File of the logger.
+// Setting no sinks writes the lines to the File again.
+//
+// The sinks are shared with the loggers derived from the logger, as they share its writes.
+// They are swapped under the lock of the writes, so every line is written to either all of the previous sinks or all of the new ones.
+//
+// The writers of the previous sinks which are an io.Closer are closed after the swap, unless they are a writer of the new sinks.
+// The first error of closing them is returned.
+func (l *Logger) SetSinks(sinks []Sink) error {
+	var s = l.shared()
+	s.mutex.Lock()
+	var previous = s.sinks
+	s.sinks = append([]Sink(nil), sinks...)
+	var formatted bool
+	for _, sink := range sinks {
+		formatted = formatted || sink.Formatter != nil
+	}
+	s.formattedSinks.Store(formatted)
+	s.mutex.Unlock()
+
+	var err error
+	for _, sink := range previous {
+		var closer, ok = sink.Writer.(io.Closer)
+		if !ok || hasWriter(sinks, sink.Writer) {
+			continue
+		}
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// hasWriter reports whether the writer is the writer of one of the sinks.
+func hasWriter(sinks []Sink, w io.Writer) bool {
+	if !reflect.TypeOf(w).Comparable() {
+		return false
+	}
+	for _, sink := range sinks {
+		if sink.Writer == w {
+			return true
+		}
+	}
+	return false
+}
+
+// A failed write of a line to a sink.
+type sinkError struct {
+	sink Sink
+	err  error
+}
+
+// writeSinks writes the line to the sinks which allow its loglevel, and returns the writes which failed.
+// Sinks with a Formatter get the formatted entry instead, if the line has one.
+//
+// The line is written to each sink with a single Write, so a sink which fails halfway does not affect the others.
+func writeSinks(sinks []Sink, level Loglevel, line []byte, entry *LogEntry) []sinkError {
+	var failed []sinkError
+	for _, sink := range sinks {
+		if !sink.allows(level) {
+			continue
+		}
+		var data = line
+		if sink.Formatter != nil {
+			if entry == nil {
+				continue
+			}
+			data = sink.Formatter.Format(entry)
+		}
+		var n, err = sink.Writer.Write(data)
+		if err == nil && n < len(data) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			failed = append(failed, sinkError{sink: sink, err: err})
+		}
+	}
+	return failed
+}
+
+// hasSinks reports whether the lines of the logger are written to sinks set with SetSinks.
+func (s *loggerState) hasSinks() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sinks) > 0
+}
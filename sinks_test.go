@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// closeBuffer is a buffer which records whether it was closed.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSetSinks(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var all, errs = &closeBuffer{}, &closeBuffer{}
+	if err := l.SetSinks([]Sink{{Writer: all}, {Writer: errs, Loglevel: ERROR}}); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("info")
+	l.Error(errors.New("boom"))
+	if buf.Len() != 0 {
+		t.Errorf("lines were written to the File with sinks:\n%s", buf.String())
+	}
+	if !strings.Contains(all.String(), "info") || !strings.Contains(all.String(), "boom") {
+		t.Errorf("sink without a loglevel did not get all lines:\n%s", all.String())
+	}
+	if strings.Contains(errs.String(), "info") || !strings.Contains(errs.String(), "boom") {
+		t.Errorf("error sink got the wrong lines:\n%s", errs.String())
+	}
+
+	var next = &closeBuffer{}
+	if err := l.SetSinks([]Sink{{Writer: all}, {Writer: next}}); err != nil {
+		t.Fatal(err)
+	}
+	if all.closed || !errs.closed {
+		t.Errorf("closed the kept sink: %t, closed the replaced sink: %t", all.closed, errs.closed)
+	}
+
+	if err := l.SetSinks(nil); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("file again")
+	if !strings.Contains(buf.String(), "file again") || strings.Contains(next.String(), "file again") {
+		t.Errorf("lines were not written to the File without sinks:\n%s", buf.String())
+	}
+}
+
+// failingWriter writes up to the limit of bytes, and fails the writes over it.
+type failingWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+var errWriterFull = errors.New("writer is full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) <= w.limit {
+		return w.Buffer.Write(p)
+	}
+	var n, _ = w.Buffer.Write(p[:w.limit-w.Len()])
+	return n, errWriterFull
+}
+
+func TestSinkWriteError(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var before, after, failing = &bytes.Buffer{}, &bytes.Buffer{}, &failingWriter{limit: 10}
+	var failed []error
+	l.OnSinkError = func(sink Sink, err error) {
+		if sink.Writer != failing {
+			t.Errorf("reported an error of the wrong sink: %v", sink)
+		}
+		failed = append(failed, err)
+	}
+	l.SetSinks([]Sink{{Writer: before}, {Writer: failing}, {Writer: after}})
+	l.Info("a line longer than the limit")
+
+	var want = "2024-01-02 03:04:05 [INFO] a line longer than the limit\n"
+	if before.String() != want || after.String() != want {
+		t.Errorf("the other sinks did not get the whole line: %q, %q", before.String(), after.String())
+	}
+	if len(failed) != 1 || !errors.Is(failed[0], errWriterFull) {
+		t.Errorf("got errors %v, want the error of the failing sink", failed)
+	}
+}
+
+func TestSinkFormatter(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var console, alerts = &bytes.Buffer{}, &bytes.Buffer{}
+	l.SetSinks([]Sink{
+		{Writer: console, MostSevere: WARNING},
+		{Writer: alerts, Loglevel: ERROR, Formatter: &JSONFormatter{}},
+	})
+
+	l.Error(errors.New("boom"))
+	if console.Len() != 0 {
+		t.Errorf("error was written to the console sink:\n%s", console.String())
+	}
+	if err := (&JSONFormatter{}).ValidateOutput(alerts.Bytes()); err != nil || !strings.Contains(alerts.String(), `"message":"boom"`) {
+		t.Errorf("error was not written as JSON to the alert sink: %v\n%s", err, alerts.String())
+	}
+
+	alerts.Reset()
+	l.Info("started")
+	if console.String() != "2024-01-02 03:04:05 [INFO] started\n" {
+		t.Errorf("info was not written as text to the console sink: %q", console.String())
+	}
+	if alerts.Len() != 0 {
+		t.Errorf("info was written to the alert sink:\n%s", alerts.String())
+	}
+}
+
+func TestSetSinksConcurrently(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	const writers, lines = 4, 200
+
+	// Each set has two sinks, which must get the same lines.
+	var sets [][2]*bytes.Buffer
+	var swap = func() {
+		var set = [2]*bytes.Buffer{{}, {}}
+		sets = append(sets, set)
+		l.SetSinks([]Sink{{Writer: set[0]}, {Writer: set[1]}})
+	}
+	swap()
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				l.Info(fmt.Sprintf("line %d-%d", w, i))
+			}
+		}(w)
+	}
+	for i := 0; i < 20; i++ {
+		swap()
+	}
+	wg.Wait()
+
+	var seen = make(map[string]int)
+	for _, set := range sets {
+		if set[0].String() != set[1].String() {
+			t.Fatalf("sinks of one set got different lines:\n%s\n%s", set[0].String(), set[1].String())
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(set[0].String(), "\n"), "\n") {
+			if line != "" {
+				seen[line[strings.Index(line, "line "):]]++
+			}
+		}
+	}
+	for w := 0; w < writers; w++ {
+		for i := 0; i < lines; i++ {
+			if n := seen[fmt.Sprintf("line %d-%d", w, i)]; n != 1 {
+				t.Fatalf("line %d-%d was written to %d sink sets, want 1", w, i, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.21
+
+package logger
+
+import "log/slog"
+
+// The slog levels of the loglevels which slog does not define.
+//
+// They continue slog's scheme, which leaves a gap of 4 between levels.
+const (
+	slogLevelCritical = slog.LevelError + 4
+	slogLevelTest     = slog.LevelDebug - 4
+)
+
+// ToSlogLevel returns the slog level of the loglevel.
+//
+// ERROR, WARNING, INFO and DEBUG map to the slog level of the same name.
+// CRITICAL maps to slog.LevelError+4, TEST to slog.LevelDebug-4.
+// Registered levels map to the slog level in between, by their severity.
+func (l Loglevel) ToSlogLevel() slog.Level {
+	switch l {
+	case CRITICAL:
+		return slogLevelCritical
+	case ERROR:
+		return slog.LevelError
+	case WARNING:
+		return slog.LevelWarn
+	case INFO:
+		return slog.LevelInfo
+	case DEBUG:
+		return slog.LevelDebug
+	case TEST:
+		return slogLevelTest
+	}
+	// Each severityStep is a step of 4 in slog, counting down from CRITICAL.
+	return slogLevelCritical - slog.Level((l.Severity()-CRITICAL.Severity())*4/severityStep)
+}
+
+// FromSlogLevel returns the loglevel of a slog level, the reverse of ToSlogLevel.
+//
+// Levels in between are rounded down to the next less severe loglevel,
+// so slog.LevelWarn+2 is a WARNING, and anything below slog.LevelDebug is TEST.
+func FromSlogLevel(level slog.Level) Loglevel {
+	switch {
+	case level >= slogLevelCritical:
+		return CRITICAL
+	case level >= slog.LevelError:
+		return ERROR
+	case level >= slog.LevelWarn:
+		return WARNING
+	case level >= slog.LevelInfo:
+		return INFO
+	case level >= slog.LevelDebug:
+		return DEBUG
+	default:
+		return TEST
+	}
+}
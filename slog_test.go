@@ -0,0 +1,57 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestToSlogLevelRegistered(t *testing.T) {
+	var notice = registerTestLevel(t, "NOTICE", 10, INFO.Severity()-5)
+	var trace = registerTestLevel(t, "TRACE", TEST+1, (TEST + 1).Severity())
+
+	if got := notice.ToSlogLevel(); got <= slog.LevelInfo || got >= slog.LevelWarn {
+		t.Errorf("NOTICE maps to %v, want a level between INFO and WARN", got)
+	}
+	if got := trace.ToSlogLevel(); got >= slogLevelTest {
+		t.Errorf("TRACE maps to %v, want a level below %v", got, slogLevelTest)
+	}
+	for _, level := range []Loglevel{CRITICAL, ERROR, WARNING, INFO, DEBUG, TEST} {
+		if got := FromSlogLevel(level.ToSlogLevel()); got != level {
+			t.Errorf("%s round-trips to %s", level, got)
+		}
+	}
+}
+
+func TestSlogLevelMapping(t *testing.T) {
+	var levels = map[Loglevel]slog.Level{
+		CRITICAL: slog.LevelError + 4,
+		ERROR:    slog.LevelError,
+		WARNING:  slog.LevelWarn,
+		INFO:     slog.LevelInfo,
+		DEBUG:    slog.LevelDebug,
+		TEST:     slog.LevelDebug - 4,
+	}
+	for level, want := range levels {
+		if got := level.ToSlogLevel(); got != want {
+			t.Errorf("%s maps to %v, want %v", level, got, want)
+		}
+		if got := FromSlogLevel(want); got != level {
+			t.Errorf("%v maps back to %s, want %s", want, got, level)
+		}
+	}
+
+	var between = map[slog.Level]Loglevel{
+		slog.LevelError + 100: CRITICAL,
+		slog.LevelWarn + 2:    WARNING,
+		slog.LevelInfo + 1:    INFO,
+		slog.LevelDebug - 1:   TEST,
+		slog.LevelDebug - 100: TEST,
+	}
+	for level, want := range between {
+		if got := FromSlogLevel(level); got != want {
+			t.Errorf("%v maps to %s, want %s", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// writeSourceContext writes the source lines around the line of a stacktrace frame.
+//
+// The line of the frame is marked with a ">".
+func writeSourceContext(b *strings.Builder, colorized bool, file string, line, context int) {
+	var data, err = os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	var lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return
+	}
+	var start, end = line - context, line + context
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	var width = len(fmt.Sprint(end))
+	for i := start; i <= end; i++ {
+		var text = fmt.Sprintf("%*d | %s", width, i, strings.ReplaceAll(lines[i-1], "\t", "    "))
+		if i == line {
+			writeIfColorized(b, colorized, "  > "+text, Bold)
+		} else {
+			writeIfColorized(b, colorized, "    "+text, DimGrey)
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/router/v3/middleware/tracer"
+)
+
+func TestSourceContext(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "main.go")
+	var source = "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n"
+	if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var e = &LogEntry{
+		Time:    testTime,
+		Level:   ERROR,
+		Message: "boom",
+		Stacktrace: tracer.StackTrace{
+			{File: file, Line: 4, FunctionName: "main.main"},
+			{File: "/does/not/exist.go", Line: 10, FunctionName: "main.missing"},
+		},
+	}
+
+	if out := e.AsString("", false); strings.Contains(out, "panic(") {
+		t.Errorf("source is rendered by default:\n%s", out)
+	}
+
+	var out = e.AsStringWith("", false, RenderOptions{SourceContext: 1})
+	for _, want := range []string{
+		"    3 | func main() {\n",
+		"  > 4 |     panic(\"boom\")\n",
+		"    5 | }\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "1 | package main") {
+		t.Errorf("more source than the context is rendered:\n%s", out)
+	}
+	if !strings.Contains(out, "Error on line 10:") {
+		t.Errorf("frame with an unreadable file is not rendered:\n%s", out)
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+)
+
+// levelWriter writes every line written to it as a message of the logger.
+type levelWriter struct {
+	logger *Logger
+	level  Loglevel
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\r\n"), []byte("\n")) {
+		w.logger.logLine(w.level, string(bytes.TrimRight(line, "\r")))
+	}
+	return len(p), nil
+}
+
+// Writer returns a writer which writes every line written to it as a message with the given loglevel.
+func (l *Logger) Writer(level Loglevel) io.Writer {
+	return &levelWriter{logger: l, level: level}
+}
+
+// CaptureStandardLog redirects the output of the standard library's log package to the logger,
+// as messages with the INFO loglevel.
+//
+// The returned errorLog writes messages with the ERROR loglevel,
+// and can be used as the ErrorLog of an http.Server.
+//
+// The undo function restores the previous output, flags and prefix of the log package.
+func (l *Logger) CaptureStandardLog() (errorLog *log.Logger, undo func()) {
+	var output, flags, prefix = log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(l.Writer(INFO))
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return log.New(l.Writer(ERROR), "", 0), func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureStandardLog(t *testing.T) {
+	restoreGlobals(t)
+	var buf = &syncBuffer{}
+	var l = NewLogger(DEBUG, buf)
+	l.Colorized = false
+	var output = log.Writer()
+	var errorLog, undo = l.CaptureStandardLog()
+
+	log.Println("from the log package")
+	if !strings.Contains(buf.String(), "[INFO] from the log package\n") {
+		t.Errorf("log.Println was not captured:\n%s", buf.String())
+	}
+
+	var server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+	server.Config.ErrorLog = errorLog
+	server.Start()
+	defer server.Close()
+	if resp, err := http.Get(server.URL); err == nil {
+		resp.Body.Close()
+	}
+	var deadline = time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "http: panic serving") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if line := lineContaining(buf.String(), "http: panic serving"); !strings.Contains(line, "[ERROR]") {
+		t.Errorf("the ErrorLog of the server was not captured as an error:\n%s", buf.String())
+	}
+
+	undo()
+	if log.Writer() != output {
+		t.Errorf("undo did not restore the output of the log package")
+	}
+}
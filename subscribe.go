@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// The amount of entries buffered per subscriber, before entries are dropped for it.
+const subscriberBuffer = 64
+
+// subscriberTracker delivers the written entries of a logger to its subscribers.
+type subscriberTracker struct {
+	mutex *sync.RWMutex
+	subs  map[chan *LogEntry]struct{}
+
+	// The amount of subscribers, checked without locking on the write path.
+	count atomic.Int32
+}
+
+func newSubscriberTracker() *subscriberTracker {
+	return &subscriberTracker{
+		mutex: &sync.RWMutex{},
+		subs:  make(map[chan *LogEntry]struct{}),
+	}
+}
+
+// active reports whether there are any subscribers.
+func (s *subscriberTracker) active() bool {
+	return s.count.Load() > 0
+}
+
+// publish delivers the entry to every subscriber which has room for it, it never blocks.
+func (s *subscriberTracker) publish(entry *LogEntry) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for ch := range s.subs {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel which receives every entry the logger writes, for example to show a live tail.
+//
+// Entries are delivered without blocking the logger, they are dropped for a subscriber which does not keep up.
+// Entries of the loggers derived from this logger are delivered too.
+// Call the returned function to unsubscribe, this closes the channel.
+func (l *Logger) Subscribe() (<-chan *LogEntry, func()) {
+	var s = l.ownScope().subscribers
+	var ch = make(chan *LogEntry, subscriberBuffer)
+	s.mutex.Lock()
+	s.subs[ch] = struct{}{}
+	s.count.Add(1)
+	s.mutex.Unlock()
+	var once = &sync.Once{}
+	return ch, func() {
+		once.Do(func() {
+			s.mutex.Lock()
+			delete(s.subs, ch)
+			s.count.Add(-1)
+			s.mutex.Unlock()
+			close(ch)
+		})
+	}
+}
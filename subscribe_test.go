@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscribeScope(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var child = l.WithFields(NewFields("request", 1))
+
+	var childEntries, unsubscribeChild = child.Subscribe()
+	defer unsubscribeChild()
+	var entries, unsubscribe = l.Subscribe()
+	defer unsubscribe()
+
+	l.Info("parent line")
+	child.Info("child line")
+
+	if len(childEntries) != 1 {
+		t.Fatalf("child subscriber received %d entries, want 1", len(childEntries))
+	}
+	if e := <-childEntries; e.Message != "child line" {
+		t.Errorf("child subscriber received %q", e.Message)
+	}
+	if len(entries) != 2 {
+		t.Errorf("parent subscriber received %d entries, want 2", len(entries))
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var entries, unsubscribe = l.Subscribe()
+	unsubscribe()
+	unsubscribe()
+	l.Info("after unsubscribe")
+	if _, ok := <-entries; ok {
+		t.Error("channel received an entry after unsubscribing")
+	}
+}
+
+func TestTwoSubscribers(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var first, unsubscribeFirst = l.Subscribe()
+	var second, unsubscribeSecond = l.Subscribe()
+	defer unsubscribeSecond()
+
+	l.Info("one")
+	l.Warning("two")
+	for name, entries := range map[string]<-chan *LogEntry{"first": first, "second": second} {
+		if len(entries) != 2 {
+			t.Fatalf("%s subscriber received %d entries, want 2", name, len(entries))
+		}
+		if e := <-entries; e.Message != "one" || e.Level != INFO {
+			t.Errorf("%s subscriber received %q at %s", name, e.Message, e.Level)
+		}
+		if e := <-entries; e.Message != "two" || e.Level != WARNING {
+			t.Errorf("%s subscriber received %q at %s", name, e.Message, e.Level)
+		}
+	}
+
+	unsubscribeFirst()
+	l.Info("three")
+	if _, ok := <-first; ok {
+		t.Error("first subscriber received an entry after unsubscribing")
+	}
+	if e := <-second; e.Message != "three" {
+		t.Errorf("second subscriber received %q, want three", e.Message)
+	}
+}
+
+func TestSlowSubscriberDrops(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var entries, unsubscribe = l.Subscribe()
+	defer unsubscribe()
+	for i := 0; i < subscriberBuffer*2; i++ {
+		l.Info("line")
+	}
+	if len(entries) != subscriberBuffer {
+		t.Errorf("slow subscriber holds %d entries, want %d", len(entries), subscriberBuffer)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != subscriberBuffer*2 {
+		t.Errorf("wrote %d lines, want all %d", got, subscriberBuffer*2)
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+// The key of the field which tags a line with its subsystem.
+const SubsystemField = "subsystem"
+
+// WithSubsystem returns a copy of the logger which tags every line with the subsystem,
+// as the "subsystem" field.
+//
+// The copy shares the writer of the logger.
+func (l *Logger) WithSubsystem(name string) *Logger {
+	return l.WithFields(NewFields(SubsystemField, name))
+}
+
+// Filter returns the entries for which the predicate returns true, in their original order.
+//
+// This can be used on captured or parsed entries, for example with BySubsystem.
+func Filter(entries []*LogEntry, predicate func(*LogEntry) bool) []*LogEntry {
+	var filtered = make([]*LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if predicate(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// BySubsystem returns a predicate for Filter, which matches the entries tagged with the subsystem.
+func BySubsystem(name string) func(*LogEntry) bool {
+	return func(entry *LogEntry) bool {
+		var value, ok = entry.Fields.Get(SubsystemField)
+		return ok && value == name
+	}
+}
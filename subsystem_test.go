@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestFilterBySubsystem(t *testing.T) {
+	var l, _ = newTestLogger(t, DEBUG)
+	var entries []*LogEntry
+	l.OnEntry = func(entry *LogEntry) {
+		entries = append(entries, entry)
+	}
+	var db, http = l.WithSubsystem("db"), l.WithSubsystem("http")
+	db.Info("connected")
+	http.Info("listening")
+	db.Warning("slow query")
+	l.Info("untagged")
+
+	var filtered = Filter(entries, BySubsystem("db"))
+	if len(filtered) != 2 || filtered[0].Message != "connected" || filtered[1].Message != "slow query" {
+		t.Errorf("filtered %d entries, want the 2 db entries in order", len(filtered))
+	}
+	if filtered = Filter(entries, BySubsystem("cache")); len(filtered) != 0 {
+		t.Errorf("filtered %d entries of an unknown subsystem", len(filtered))
+	}
+}
@@ -0,0 +1,41 @@
+package logger
+
+import "strings"
+
+// Table writes a table of key/value rows under the title, as a single entry with the given loglevel.
+//
+// The keys and values are written in aligned columns, by the amount of columns they take up in a terminal.
+//
+//	l.Table(logger.INFO, "config", [][2]string{{"addr", ":8080"}, {"workers", "4"}})
+func (l *Logger) Table(level Loglevel, title string, rows [][2]string) {
+	if !l.enabled(level) {
+		return
+	}
+	l.logLine(level, table(title, rows, l.TableKeysRight))
+}
+
+// table returns the lines of the table, the key column is as wide as the widest key.
+func table(title string, rows [][2]string, keysRight bool) string {
+	var width int
+	for _, row := range rows {
+		if w := VisibleWidth(row[0]); w > width {
+			width = w
+		}
+	}
+	var b = &strings.Builder{}
+	b.WriteString(title)
+	for _, row := range rows {
+		var padding = strings.Repeat(" ", width-VisibleWidth(row[0]))
+		b.WriteString("\n  ")
+		if keysRight {
+			b.WriteString(padding)
+			b.WriteString(row[0])
+		} else {
+			b.WriteString(row[0])
+			b.WriteString(padding)
+		}
+		b.WriteString("  ")
+		b.WriteString(row[1])
+	}
+	return b.String()
+}
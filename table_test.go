@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var rows = [][2]string{
+		{"addr", ":8080"},
+		{"workers", "4"},
+		{"名前", "app"},
+		{"x", "value with spaces"},
+	}
+	for _, keysRight := range []bool{false, true} {
+		var l, buf = newTestLogger(t, DEBUG)
+		l.TableKeysRight = keysRight
+		l.Table(INFO, "config", rows)
+
+		var lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		var rowLines = lines[len(lines)-len(rows):]
+		for i, line := range rowLines {
+			var value = rows[i][1]
+			// The value column starts after the widest key, its padding and the indentation.
+			if !strings.HasSuffix(line, value) || VisibleWidth(line)-VisibleWidth(value) != 2+7+2 {
+				t.Errorf("keys right %t: value of %q does not start at column 11:\n%s", keysRight, line, buf.String())
+			}
+			var key, padding = rows[i][0], strings.Repeat(" ", 7-VisibleWidth(rows[i][0]))
+			var want = "  " + key + padding
+			if keysRight {
+				want = "  " + padding + key
+			}
+			if !strings.HasPrefix(line, want+"  "+value) {
+				t.Errorf("keys right %t: got %q, want the row %q", keysRight, line, want+"  "+value)
+			}
+		}
+	}
+}
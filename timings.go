@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The context key of the timings of a request.
+type timingsKey struct{}
+
+// timings collects named durations, such as the time spent in each middleware of a request.
+type timings struct {
+	mutex  *sync.Mutex
+	fields Fields
+}
+
+// WithTimings returns a context which collects the timings recorded with RecordTiming.
+//
+// This is meant to be called once at the start of a request.
+func WithTimings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timingsKey{}, &timings{mutex: &sync.Mutex{}})
+}
+
+// RecordTiming records the duration of a named step, such as a middleware, on the context.
+//
+// Durations recorded under the same name are added up.
+// If the context was not created with WithTimings, the timing is ignored.
+func RecordTiming(ctx context.Context, name string, d time.Duration) {
+	var t, ok = ctx.Value(timingsKey{}).(*timings)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if v, ok := t.fields.Get(name); ok {
+		d += v.(time.Duration)
+	}
+	t.fields = t.fields.With(name, d)
+}
+
+// TimingFields returns the timings recorded on the context as a "timings" field,
+// in the order they were first recorded.
+//
+// The field can be added to the access line of the request with WithFields:
+//
+//	l.WithFields(logger.TimingFields(r.Context())).Info("GET /")
+func TimingFields(ctx context.Context) Fields {
+	var t, ok = ctx.Value(timingsKey{}).(*timings)
+	if !ok {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.fields) == 0 {
+		return nil
+	}
+	return NewFields("timings", timingValues(t.fields))
+}
+
+// The value of the "timings" field, written as {name=duration ...} in text.
+type timingValues Fields
+
+func (t timingValues) String() string {
+	return "{" + Fields(t).String() + "}"
+}
+
+func (t timingValues) MarshalJSON() ([]byte, error) {
+	return Fields(t).MarshalJSON()
+}
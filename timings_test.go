@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingFields(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RecordTiming(r.Context(), "handler", 40*time.Millisecond)
+	})
+	// An access middleware, with an auth step which records its own timing.
+	var access = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithTimings(r.Context()))
+		RecordTiming(r.Context(), "auth", 2*time.Millisecond)
+		handler.ServeHTTP(w, r)
+		RecordTiming(r.Context(), "auth", time.Millisecond)
+		l.WithFields(TimingFields(r.Context())).Infof("%s %s\n", r.Method, r.URL.Path)
+	})
+	access.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+
+	if want := "[INFO] GET /users timings={auth=3ms handler=40ms}\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("got %q, want suffix %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.Formatter = &JSONFormatter{}
+	access.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	if want := `"fields":{"timings":{"auth":"3ms","handler":"40ms"}}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("got %s, want it to contain %s", buf.String(), want)
+	}
+}
+
+func TestRecordTimingWithoutTimings(t *testing.T) {
+	RecordTiming(context.Background(), "auth", time.Millisecond)
+	if f := TimingFields(context.Background()); f != nil {
+		t.Errorf("got fields %v without WithTimings", f)
+	}
+}
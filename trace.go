@@ -0,0 +1,29 @@
+package logger
+
+import "github.com/Nigel2392/router/v3/middleware/tracer"
+
+// The note written instead of a stacktrace which could not be captured.
+const stacktraceUnavailable = "[stacktrace unavailable]"
+
+// The functions of the tracer package used to capture stacktraces, replaceable in tests.
+var (
+	tracerTrace     = tracer.Trace
+	tracerTraceSafe = tracer.TraceSafe
+)
+
+// captureTrace captures the stacktrace of an error, with tracer.TraceSafe if safe is set, otherwise with tracer.Trace.
+//
+// If the tracer panics, the panic is recovered and ok is false,
+// so a failure to trace never takes down the logging call.
+func captureTrace(safe bool, err error, stackLen, skip int) (trace tracer.StackTrace, ok bool) {
+	defer func() {
+		if recover() != nil {
+			trace, ok = nil, false
+		}
+	}()
+	var fn = tracerTrace
+	if safe {
+		fn = tracerTraceSafe
+	}
+	return fn(err, stackLen, skip+1).Trace(), true
+}
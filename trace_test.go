@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/router/v3/middleware/tracer"
+)
+
+// panickingTracer replaces the tracer with one which panics, until the test ends.
+func panickingTracer(t *testing.T) {
+	var trace, traceSafe = tracerTrace, tracerTraceSafe
+	t.Cleanup(func() { tracerTrace, tracerTraceSafe = trace, traceSafe })
+	var panics = func(err error, stackLen, skip int) tracer.ErrorType {
+		panic("tracer failed")
+	}
+	tracerTrace, tracerTraceSafe = panics, panics
+}
+
+func TestPanickingTracer(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	panickingTracer(t)
+
+	l.Error(errors.New("boom"))
+	l.Critical(errors.New("critical boom"))
+	for _, want := range []string{"[ERROR] boom " + stacktraceUnavailable, "[CRITICAL] critical boom " + stacktraceUnavailable} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Stacktrace:") {
+		t.Errorf("a stacktrace was written:\n%s", buf.String())
+	}
+
+	var e = NewLogEntry(ERROR, "entry", 8, 0)
+	if e.Stacktrace != nil || e.Message != "entry "+stacktraceUnavailable {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
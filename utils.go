@@ -1,6 +1,9 @@
 package logger
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 // Cut the front of a path, and add "..." if it was cut.
 func CutFrontPath(s string, length int) string {
@@ -23,3 +26,85 @@ func CutStart(s string, length int, delim string, prefixIfCut bool) string {
 	}
 	return s
 }
+
+// Cut the end of the string if it is wider than the specified amount of columns, and add "…" if it was cut.
+//
+// The width is measured with VisibleWidth, the string is never cut in the middle of a rune.
+func CutEnd(s string, width int) string {
+	if VisibleWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	var used int
+	for _, r := range s {
+		var w = runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		used += w
+		b.WriteRune(r)
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// Replace all line endings in s with the terminator, see RenderOptions.LineTerminator.
+//
+// Existing "\r\n" line endings are normalized first, so terminators are never doubled up.
+func terminateLines(s, terminator string) string {
+	if terminator == "" || terminator == "\n" {
+		return s
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", terminator)
+}
+
+// VisibleWidth returns the amount of columns the string takes up when displayed in a terminal.
+//
+// ANSI color codes are removed first, East-Asian wide characters count as two columns,
+// combining marks and other zero-width characters as none.
+func VisibleWidth(s string) int {
+	var width int
+	for _, r := range DeColorize(s) {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// Ranges of East-Asian wide and fullwidth characters.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK Radicals, Kangxi, CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth Signs
+	{0x1F300, 0x1F64F}, // Pictographs and Emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x20000, 0x2FFFD}, // CJK Unified Ideographs Extension B and later
+	{0x30000, 0x3FFFD}, // CJK Unified Ideographs Extension G and later
+}
+
+// runeWidth returns the amount of columns a rune takes up when displayed.
+func runeWidth(r rune) int {
+	if r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r) || unicode.IsControl(r) {
+		return 0
+	}
+	for _, rng := range wideRanges {
+		if r < rng.lo {
+			break
+		}
+		if r <= rng.hi {
+			return 2
+		}
+	}
+	return 1
+}
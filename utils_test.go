@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	var tests = []struct {
+		name string
+		s    string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello world", 11},
+		{"colorized", Colorize("hello", Red, Bold), 5},
+		{"cjk", "日本語", 6},
+		{"colorized cjk", Colorize("日本", Red) + "ab", 6},
+		{"combining marks", "e\u0301e\u0301", 2},
+		{"fullwidth", "ＡＢ", 4},
+	}
+	for _, test := range tests {
+		if got := VisibleWidth(test.s); got != test.want {
+			t.Errorf("%s: VisibleWidth(%q) = %d, want %d", test.name, test.s, got, test.want)
+		}
+	}
+}
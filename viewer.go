@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"html"
+	"net/http"
+	"strings"
+)
+
+// CSS classes for the ANSI codes used by the logger.
+var ansiClasses = map[string]string{
+	Italics:   "italics",
+	Underline: "underline",
+	Blink:     "blink",
+	Bold:      "bold",
+
+	Red:    "red",
+	Green:  "green",
+	Yellow: "yellow",
+	Blue:   "blue",
+	Purple: "purple",
+	Cyan:   "cyan",
+	White:  "white",
+	Grey:   "grey",
+
+	BrightRed:    "bright-red",
+	BrightGreen:  "bright-green",
+	BrightYellow: "bright-yellow",
+	BrightBlue:   "bright-blue",
+	BrightPurple: "bright-purple",
+	BrightCyan:   "bright-cyan",
+	BrightGrey:   "bright-grey",
+
+	DimRed:    "dim-red",
+	DimGreen:  "dim-green",
+	DimYellow: "dim-yellow",
+	DimBlue:   "dim-blue",
+	DimPurple: "dim-purple",
+	DimCyan:   "dim-cyan",
+	DimGrey:   "dim-grey",
+}
+
+const viewerStyle = `body{background:#1e1e1e;color:#d4d4d4;margin:0}
+pre{font-family:monospace;padding:1em;margin:0;white-space:pre-wrap}
+.italics{font-style:italic}.underline{text-decoration:underline}.bold,[class^=bright-]{font-weight:bold}
+.blink{animation:blink 1s step-start infinite}@keyframes blink{50%{opacity:0}}
+[class^=dim-]{opacity:.6}
+.red,.bright-red,.dim-red{color:#f14c4c}.green,.bright-green,.dim-green{color:#23d18b}
+.yellow,.bright-yellow,.dim-yellow{color:#f5f543}.blue,.bright-blue,.dim-blue{color:#3b8eea}
+.purple,.bright-purple,.dim-purple{color:#d670d6}.cyan,.bright-cyan,.dim-cyan{color:#29b8db}
+.white{color:#e5e5e5}.grey,.bright-grey,.dim-grey{color:#a0a0a0}`
+
+// LogViewerHandler returns a handler which renders the lines in the ring buffer as HTML.
+//
+// The lines can be filtered with the "level" query parameter,
+// only entries at or above the severity of that level are rendered.
+func LogViewerHandler(rb *RingBufferWriter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var maxLevel = TEST
+		if q := r.URL.Query().Get("level"); q != "" {
+			maxLevel = levelFromName(q)
+			if maxLevel == 0 {
+				http.Error(w, "unknown loglevel: "+q, http.StatusBadRequest)
+				return
+			}
+		}
+
+		var b = &strings.Builder{}
+		b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Logs</title><style>")
+		b.WriteString(viewerStyle)
+		b.WriteString("</style></head><body><pre>")
+
+		// Lines which cannot be parsed belong to the entry before them, such as stacktraces.
+		var include bool
+		for _, line := range rb.Lines() {
+			if entry, err := ParseLine(line); err == nil {
+				include = entry.Level.AtLeast(maxLevel)
+			}
+			if include {
+				b.WriteString(ansiToHTML(line))
+			}
+		}
+
+		b.WriteString("</pre></body></html>\n")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(b.String()))
+	})
+}
+
+// levelFromName returns the built-in or registered loglevel with the given name, case insensitive,
+// or 0 if there is none.
+func levelFromName(name string) Loglevel {
+	var level, err = ParseLoglevel(name)
+	if err != nil {
+		return 0
+	}
+	return level
+}
+
+// Convert the ANSI color codes in s to HTML spans, escaping the text.
+func ansiToHTML(s string) string {
+	var b = &strings.Builder{}
+	var open int
+	var last int
+	for _, loc := range remAnsiRex.FindAllStringIndex(s, -1) {
+		b.WriteString(html.EscapeString(s[last:loc[0]]))
+		last = loc[1]
+		var code = s[loc[0]:loc[1]]
+		if code == Reset {
+			for ; open > 0; open-- {
+				b.WriteString("</span>")
+			}
+			continue
+		}
+		if class, ok := ansiClasses[code]; ok {
+			b.WriteString(`<span class="`)
+			b.WriteString(class)
+			b.WriteString(`">`)
+			open++
+		}
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	for ; open > 0; open-- {
+		b.WriteString("</span>")
+	}
+	return b.String()
+}
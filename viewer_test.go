@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// viewLogs returns the body of the LogViewerHandler for the query.
+func viewLogs(rb *RingBufferWriter, query string) (int, string) {
+	var rec = httptest.NewRecorder()
+	LogViewerHandler(rb).ServeHTTP(rec, httptest.NewRequest("GET", "/logs"+query, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestLogViewerRegisteredLevel(t *testing.T) {
+	var notice = registerTestLevel(t, "NOTICE", 10, INFO.Severity()-5)
+	var rb = NewRingBufferWriter(10)
+	var l, _ = newTestLogger(t, DEBUG)
+	l.File = rb
+	l.Info("info line")
+	l.LogAt(testTime, notice, "notice line")
+	l.Warning("warning line")
+
+	var code, body = viewLogs(rb, "?level=notice")
+	if code != 200 {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	if strings.Contains(body, "info line") || !strings.Contains(body, "notice line") || !strings.Contains(body, "warning line") {
+		t.Errorf("unexpected lines at level NOTICE:\n%s", body)
+	}
+
+	if code, _ = viewLogs(rb, "?level=bogus"); code != 400 {
+		t.Errorf("unknown level gave status %d, want 400", code)
+	}
+}
+
+func TestLogViewerLevelFilter(t *testing.T) {
+	var rb = NewRingBufferWriter(100)
+	var l, _ = newTestLogger(t, DEBUG)
+	l.File = rb
+	l.Colorized = true
+	l.Info("info <line>")
+	l.Error("error line")
+	l.Critical(errors.New("critical line"))
+
+	var _, body = viewLogs(rb, "?level=error")
+	if strings.Contains(body, "info") {
+		t.Errorf("info line is rendered at level error:\n%s", body)
+	}
+	if !strings.Contains(body, "error line") || !strings.Contains(body, "critical line") {
+		t.Errorf("error lines are missing:\n%s", body)
+	}
+	if !strings.Contains(body, "Stacktrace:") {
+		t.Errorf("stacktrace of the error is missing:\n%s", body)
+	}
+	if strings.Contains(body, "\x1b[") || !strings.Contains(body, `<span class="`) {
+		t.Errorf("colors are not converted to spans:\n%s", body)
+	}
+
+	_, body = viewLogs(rb, "")
+	if !strings.Contains(body, "info &lt;line&gt;") {
+		t.Errorf("info line is missing or not escaped without a filter:\n%s", body)
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// volumeTracker counts the lines written per second, to warn about log storms.
+type volumeTracker struct {
+	mutex *sync.Mutex
+
+	// The start of the current second, and the lines written in it.
+	second time.Time
+	count  int
+
+	// The time the last warning was written.
+	warned time.Time
+}
+
+func newVolumeTracker() *volumeTracker {
+	return &volumeTracker{mutex: &sync.Mutex{}}
+}
+
+// track counts a line, and reports whether a warning should be written.
+//
+// A warning is only reported once per window.
+func (v *volumeTracker) track(now time.Time, threshold int, window time.Duration) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if now.Sub(v.second) >= time.Second {
+		v.second = now
+		v.count = 0
+	}
+	v.count++
+	if v.count <= threshold || (!v.warned.IsZero() && now.Sub(v.warned) < window) {
+		return false
+	}
+	v.warned = now
+	return true
+}
+
+// trackVolume counts a written line, and writes a warning if the VolumeWarnThreshold is exceeded.
+func (l *Logger) trackVolume(t time.Time) {
+	var window = l.VolumeWarnWindow
+	if window <= 0 {
+		window = time.Minute
+	}
+	if l.shared().volume.track(time.Now(), l.VolumeWarnThreshold, window) {
+		l.writeRaw(t, WARNING, l.render(t, WARNING, fmt.Sprintf(
+			"log volume exceeded %d lines per second\n", l.VolumeWarnThreshold,
+		)), nil, false)
+	}
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVolumeWarningOnce(t *testing.T) {
+	var l, buf = newTestLogger(t, DEBUG)
+	l.VolumeWarnThreshold = 10
+	for i := 0; i < 100; i++ {
+		l.Info("storm")
+	}
+	if got := strings.Count(buf.String(), "log volume exceeded 10 lines per second"); got != 1 {
+		t.Errorf("warned %d times, want once", got)
+	}
+}
+
+func TestVolumeTrackerWindow(t *testing.T) {
+	var v = newVolumeTracker()
+	var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var warnings int
+	// 5 lines per 100ms is 50 lines per second, for 3 minutes.
+	for i := 0; i < 1800; i++ {
+		var now = start.Add(time.Duration(i) * 100 * time.Millisecond)
+		for j := 0; j < 5; j++ {
+			if v.track(now, 20, time.Minute) {
+				warnings++
+			}
+		}
+	}
+	if warnings != 3 {
+		t.Errorf("warned %d times in 3 minutes, want once per minute", warnings)
+	}
+}